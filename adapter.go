@@ -0,0 +1,16 @@
+package alice
+
+import "net/http"
+
+// WrapNegroni adapts negroni-style middleware,
+// which receives the next handler on every request,
+// to a Constructor.
+//
+//	alice.New(alice.WrapNegroni(negroniLogger.ServeHTTP)).Then(h)
+func WrapNegroni(m func(http.ResponseWriter, *http.Request, http.HandlerFunc)) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m(w, r, h.ServeHTTP)
+		})
+	}
+}
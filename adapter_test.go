@@ -0,0 +1,29 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrapNegroniCallsNextCorrectly(t *testing.T) {
+	negroni := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		w.Write([]byte("before\n"))
+		next(w, r)
+		w.Write([]byte("after\n"))
+	}
+
+	chained := New(tagMiddleware("t1\n"), WrapNegroni(negroni), tagMiddleware("t2\n")).Then(testApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nbefore\nt2\napp\nafter\n" {
+		t.Error("WrapNegroni does not call the next handler correctly")
+	}
+}
@@ -0,0 +1,58 @@
+// Package alicetest provides utilities for testing alice chains.
+package alicetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/containous/alice"
+)
+
+// AssertOrder serves a request with the chain
+// and reports an error to t unless the request
+// went through its middleware in the expected order.
+//
+// Middleware is identified by the name it was given with alice.Named,
+// or else by its index in the chain, such as "#1".
+// Middleware that does not pass the request on
+// cuts the order short.
+//
+//	chain := alice.New(alice.Named("logger", logger), auth)
+//	alicetest.AssertOrder(t, chain, []string{"logger", "#1"})
+func AssertOrder(t testing.TB, c alice.Chain, expected []string) {
+	t.Helper()
+
+	names := make([]string, 0, c.Len())
+	c.ForEach(func(i int, constructor alice.Constructor) {
+		if name, ok := alice.Name(constructor); ok {
+			names = append(names, name)
+		} else {
+			names = append(names, "#"+strconv.Itoa(i))
+		}
+	})
+
+	var visited []string
+	next := 0
+	traced := c.Map(func(constructor alice.Constructor) alice.Constructor {
+		name := names[next]
+		next++
+
+		return func(h http.Handler) http.Handler {
+			wrapped := constructor(h)
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				visited = append(visited, name)
+				wrapped.ServeHTTP(w, r)
+			})
+		}
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	traced.ThenFunc(func(http.ResponseWriter, *http.Request) {}).ServeHTTP(httptest.NewRecorder(), r)
+
+	if strings.Join(visited, " -> ") != strings.Join(expected, " -> ") {
+		t.Errorf("requests go %s, expected %s", strings.Join(visited, " -> "), strings.Join(expected, " -> "))
+	}
+}
@@ -0,0 +1,61 @@
+package alicetest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/containous/alice"
+)
+
+// recorder is a testing.TB recording the errors reported to it.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func passing(h http.Handler) http.Handler {
+	return h
+}
+
+func stopping(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+}
+
+func TestAssertOrderAcceptsCorrectOrder(t *testing.T) {
+	rec := &recorder{TB: t}
+	chain := alice.New(alice.Named("first", passing), passing, alice.Named("last", passing))
+
+	AssertOrder(rec, chain, []string{"first", "#1", "last"})
+
+	if len(rec.errors) != 0 {
+		t.Errorf("AssertOrder should accept a correct order: %v", rec.errors)
+	}
+}
+
+func TestAssertOrderRejectsWrongOrder(t *testing.T) {
+	tests := []struct {
+		chain    alice.Chain
+		expected []string
+	}{
+		{alice.New(alice.Named("first", passing), alice.Named("last", passing)), []string{"last", "first"}},
+		{alice.New(alice.Named("first", passing), alice.Named("last", passing)).Reversed(), []string{"first", "last"}},
+		{alice.New(alice.Named("first", stopping), alice.Named("last", passing)), []string{"first", "last"}},
+	}
+
+	for _, test := range tests {
+		rec := &recorder{TB: t}
+
+		AssertOrder(rec, test.chain, test.expected)
+
+		if len(rec.errors) != 1 {
+			t.Errorf("AssertOrder should reject %v for %s", test.expected, test.chain)
+		}
+	}
+}
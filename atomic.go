@@ -0,0 +1,59 @@
+package alice
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// AtomicChain is an http.Handler serving requests
+// with a handler that can be replaced at any time,
+// such as a chain rebuilt after a configuration reload.
+//
+// Replacing the handler does not affect requests already being served,
+// and serving requests takes no lock.
+// The zero value serves requests with http.DefaultServeMux.
+// An AtomicChain must not be copied after first use.
+type AtomicChain struct {
+	v atomic.Value
+}
+
+// atomicHandler wraps the handlers stored in an AtomicChain,
+// since atomic.Value requires values of a consistent type.
+type atomicHandler struct {
+	h http.Handler
+}
+
+// Store replaces the handler serving requests.
+// Store treats nil as http.DefaultServeMux.
+func (a *AtomicChain) Store(h http.Handler) {
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+	a.v.Store(atomicHandler{h})
+}
+
+// Load returns the handler currently serving requests.
+func (a *AtomicChain) Load() http.Handler {
+	if stored, ok := a.v.Load().(atomicHandler); ok {
+		return stored.h
+	}
+	return http.DefaultServeMux
+}
+
+// ServeHTTP serves the request with the current handler.
+func (a *AtomicChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.Load().ServeHTTP(w, r)
+}
+
+// BuildAtomic chains the middleware as Then does,
+// and returns an AtomicChain initially serving the result.
+//
+//	active := stdChain.BuildAtomic(app)
+//	go http.ListenAndServe(":8000", active)
+//	// later, on reload:
+//	active.Store(newChain.Then(app))
+func (c Chain) BuildAtomic(h http.Handler) *AtomicChain {
+	a := &AtomicChain{}
+	a.Store(c.Then(h))
+	return a
+}
@@ -0,0 +1,84 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestAtomicChainServesStoredHandler(t *testing.T) {
+	a := New(tagMiddleware("t1\n")).BuildAtomic(testApp)
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, r)
+	if w.Body.String() != "t1\napp\n" {
+		t.Error("AtomicChain does not serve the built handler")
+	}
+
+	a.Store(New(tagMiddleware("t2\n")).Then(testApp))
+
+	w = httptest.NewRecorder()
+	a.ServeHTTP(w, r)
+	if w.Body.String() != "t2\napp\n" {
+		t.Error("AtomicChain does not serve the stored handler")
+	}
+}
+
+func TestAtomicChainTreatsNilAsDefaultServeMux(t *testing.T) {
+	var a AtomicChain
+	if a.Load() != http.DefaultServeMux {
+		t.Error("AtomicChain zero value does not serve DefaultServeMux")
+	}
+
+	a.Store(nil)
+	if a.Load() != http.DefaultServeMux {
+		t.Error("Store does not treat nil as DefaultServeMux")
+	}
+}
+
+func TestAtomicChainSwapsConcurrently(t *testing.T) {
+	h1 := New(tagMiddleware("t1\n")).Then(testApp)
+	h2 := New(tagMiddleware("t2\n")).Then(testApp)
+	a := New().BuildAtomic(h1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if (i+j)%2 == 0 {
+					a.Store(h1)
+				} else {
+					a.Store(http.NewServeMux())
+					a.Store(h2)
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				w := httptest.NewRecorder()
+				r, err := http.NewRequest("GET", "/", nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				a.ServeHTTP(w, r)
+
+				body := w.Body.String()
+				if body != "t1\napp\n" && body != "t2\napp\n" && w.Code != http.StatusNotFound {
+					t.Errorf("AtomicChain serves an unexpected handler: %q", body)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+package alice
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// BasicAuth returns a constructor for middleware
+// checking the HTTP basic authentication credentials of requests
+// with check, which reports whether they are valid.
+// Requests without valid credentials get a 401 Unauthorized response
+// asking for credentials for realm.
+//
+//	alice.New(alice.BasicAuth("admin", alice.BasicAuthUsers(map[string]string{
+//		"alice": "secret",
+//	}))).Then(h)
+func BasicAuth(realm string, check func(user, pass string) bool) Constructor {
+	challenge := `Basic realm="` + strings.ReplaceAll(realm, `"`, `\"`) + `", charset="UTF-8"`
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || !check(user, pass) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BasicAuthUsers returns a check function for BasicAuth
+// accepting the users of the given user to password map.
+// Passwords are compared in constant time,
+// so that response times do not leak them.
+func BasicAuthUsers(users map[string]string) func(user, pass string) bool {
+	hashes := make(map[string][sha256.Size]byte, len(users))
+	for user, pass := range users {
+		hashes[user] = sha256.Sum256([]byte(pass))
+	}
+
+	return func(user, pass string) bool {
+		want, ok := hashes[user]
+		got := sha256.Sum256([]byte(pass))
+		return subtle.ConstantTimeCompare(got[:], want[:]) == 1 && ok
+	}
+}
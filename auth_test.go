@@ -0,0 +1,43 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+	chained := New(BasicAuth("admin", BasicAuthUsers(map[string]string{
+		"alice": "secret",
+	}))).Then(testApp)
+
+	tests := []struct {
+		name       string
+		user, pass string
+		code       int
+	}{
+		{"missing credentials", "", "", http.StatusUnauthorized},
+		{"wrong password", "alice", "guess", http.StatusUnauthorized},
+		{"unknown user", "bob", "secret", http.StatusUnauthorized},
+		{"correct credentials", "alice", "secret", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.user != "" {
+			r.SetBasicAuth(test.user, test.pass)
+		}
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, r)
+
+		if w.Code != test.code {
+			t.Errorf("%s: got %d, expected %d", test.name, w.Code, test.code)
+		}
+		if test.code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != `Basic realm="admin", charset="UTF-8"` {
+			t.Errorf("%s: WWW-Authenticate is %q", test.name, w.Header().Get("WWW-Authenticate"))
+		}
+		if test.code == http.StatusOK && w.Body.String() != "app\n" {
+			t.Errorf("%s: next handler did not run", test.name)
+		}
+	}
+}
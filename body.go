@@ -0,0 +1,167 @@
+package alice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MaxBytes returns a constructor for middleware
+// limiting request bodies to n bytes with http.MaxBytesReader:
+// reading past the limit fails with an *http.MaxBytesError.
+//
+//	alice.New(alice.MaxBytes(1<<20)).Then(h)
+func MaxBytes(n int64) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MaxBytesReject works like MaxBytes,
+// but also responds 413 Request Entity Too Large to requests
+// whose body is over the limit:
+// requests declaring a larger Content-Length do not reach the next handler,
+// and the response of the next handler is replaced
+// if it reads past the limit before writing it.
+//
+//	alice.New(alice.MaxBytesReject(1<<20)).Then(h)
+func MaxBytesReject(n int64) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				tooLarge(w)
+				return
+			}
+
+			rw := &rejectWriter{ResponseWriter: w}
+			r.Body = &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, n), exceeded: &rw.exceeded}
+			h.ServeHTTP(rw, r)
+
+			if rw.exceeded && !rw.wrote && !rw.rejected {
+				rw.reject()
+			}
+		})
+	}
+}
+
+// tooLarge responds 413 Request Entity Too Large.
+func tooLarge(w http.ResponseWriter) {
+	http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+}
+
+// limitedBody records whether reading it went past its limit.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded *bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		*b.exceeded = true
+	}
+	return n, err
+}
+
+// rejectWriter replaces the response of a handler
+// that read past the body limit before writing it
+// with a 413 response, discarding what the handler writes.
+type rejectWriter struct {
+	http.ResponseWriter
+	exceeded bool
+	wrote    bool
+	rejected bool
+}
+
+func (w *rejectWriter) reject() {
+	w.rejected = true
+	w.Header().Del("Content-Length")
+	w.Header().Del("Content-Encoding")
+	tooLarge(w.ResponseWriter)
+}
+
+func (w *rejectWriter) WriteHeader(code int) {
+	if w.rejected {
+		return
+	}
+	if !w.wrote && w.exceeded {
+		w.reject()
+		return
+	}
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *rejectWriter) Write(b []byte) (int, error) {
+	if !w.rejected && !w.wrote && w.exceeded {
+		w.reject()
+	}
+	if w.rejected {
+		return len(b), nil
+	}
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *rejectWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// BufferBody returns a constructor for middleware
+// reading request bodies of up to maxBytes bytes in full
+// before calling the next handler,
+// so that they can be read both from BufferedBody
+// and from the request body again,
+// requests without a body having an empty one.
+// Requests with a larger body get a 413 Request Entity Too Large response,
+// and requests whose body cannot be read a 400 Bad Request response,
+// without reaching the next handler.
+//
+//	alice.New(alice.BufferBody(1<<20), verifySignature).Then(h)
+func BufferBody(maxBytes int64) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				var err error
+				body, err = io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+				r.Body.Close()
+				if err != nil {
+					http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+					return
+				}
+				if int64(len(body)) > maxBytes {
+					tooLarge(w)
+					return
+				}
+			}
+			if body == nil {
+				body = []byte{}
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), bufferedBodyKey{}, body))
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bufferedBodyKey is the context key under which
+// BufferBody stores request bodies.
+type bufferedBodyKey struct{}
+
+// BufferedBody returns the request body stored in ctx
+// by the middleware returned by BufferBody,
+// or nil if there is none.
+// The returned slice must not be modified.
+func BufferedBody(ctx context.Context) []byte {
+	body, _ := ctx.Value(bufferedBodyKey{}).([]byte)
+	return body
+}
@@ -0,0 +1,127 @@
+package alice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBytesLimitsBody(t *testing.T) {
+	var read string
+	var err error
+	chained := New(MaxBytes(4)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b []byte
+		b, err = io.ReadAll(r.Body)
+		read = string(b)
+	})
+
+	chained.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader("hello")))
+
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) || read != "hell" {
+		t.Errorf("MaxBytes let %q through, with error %v", read, err)
+	}
+}
+
+func TestMaxBytesRejectRejectsDeclaredLength(t *testing.T) {
+	called := false
+	chained := New(MaxBytesReject(4)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("hello")))
+
+	if w.Code != http.StatusRequestEntityTooLarge || called {
+		t.Errorf("MaxBytesReject should respond 413, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("hey")))
+
+	if w.Code != http.StatusOK || !called {
+		t.Errorf("MaxBytesReject rejected a body within the limit with %d", w.Code)
+	}
+}
+
+func TestMaxBytesRejectRejectsUndeclaredLength(t *testing.T) {
+	readBody := func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("read\n"))
+	}
+	chained := New(MaxBytesReject(4)).ThenFunc(readBody)
+
+	for body, code := range map[string]int{
+		"hello": http.StatusRequestEntityTooLarge,
+		"hey":   http.StatusOK,
+	} {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, r)
+
+		if w.Code != code {
+			t.Errorf("MaxBytesReject responded %d to %q, expected %d", w.Code, body, code)
+		}
+		if code == http.StatusRequestEntityTooLarge && w.Body.String() != http.StatusText(code)+"\n" {
+			t.Errorf("MaxBytesReject kept the response of the handler: %q", w.Body.String())
+		}
+	}
+}
+
+func TestBufferBodyReplaysBody(t *testing.T) {
+	var buffered, read []byte
+	verify := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buffered = BufferedBody(r.Context())
+			h.ServeHTTP(w, r)
+		})
+	}
+	chained := New(BufferBody(5), verify).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		read, _ = io.ReadAll(r.Body)
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("hello")))
+
+	if string(buffered) != "hello" || string(read) != "hello" {
+		t.Errorf("BufferBody buffered %q, next handler read %q", buffered, read)
+	}
+}
+
+func TestBufferBodyRejectsLargeBodies(t *testing.T) {
+	called := false
+	chained := New(BufferBody(4)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("hello")))
+
+	if w.Code != http.StatusRequestEntityTooLarge || called {
+		t.Errorf("BufferBody should respond 413, got %d", w.Code)
+	}
+}
+
+func TestBufferedBodyWithoutBody(t *testing.T) {
+	var buffered []byte
+	chained := New(BufferBody(4)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		buffered = BufferedBody(r.Context())
+	})
+
+	chained.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if buffered == nil || len(buffered) != 0 {
+		t.Errorf("BufferedBody returned %q for a request without body", buffered)
+	}
+	if BufferedBody(context.Background()) != nil {
+		t.Error("BufferedBody returned a body outside of BufferBody")
+	}
+}
@@ -0,0 +1,61 @@
+package alice
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// BuildContextConstructor is a constructor for middleware
+// receiving the build context given to Chain.ThenWith.
+type BuildContextConstructor func(next http.Handler, buildCtx interface{}) http.Handler
+
+// WithBuildContext adapts a BuildContextConstructor to a Constructor.
+//
+// When the chain is built by ThenWith, the constructor receives its build context.
+// It receives a nil build context when the chain is built otherwise,
+// when it is wrapped in helpers other than Named or Prioritized,
+// or when it is called on its own.
+//
+//go:noinline
+func WithBuildContext(c BuildContextConstructor) Constructor {
+	// The returned closure is recognized by acceptsBuildProbe through its code pointer,
+	// which is why WithBuildContext must not be inlined.
+	return func(h http.Handler) http.Handler {
+		if p, ok := h.(*buildProbe); ok {
+			return c(p.next, p.buildCtx)
+		}
+		return c(h, nil)
+	}
+}
+
+// buildContextPointer is the code pointer shared by all constructors returned by WithBuildContext.
+var buildContextPointer uintptr
+
+func init() {
+	buildContextPointer = reflect.ValueOf(WithBuildContext(nil)).Pointer()
+}
+
+// acceptsBuildProbe reports whether c knows how to handle a buildProbe.
+func acceptsBuildProbe(c Constructor) bool {
+	if c == nil {
+		return false
+	}
+
+	p := reflect.ValueOf(c).Pointer()
+	return p == indexedPointer || p == buildContextPointer || p == annotatedPointer
+}
+
+// buildProbe is passed to constructors created by Indexed and WithBuildContext
+// to tell them about the chain being built.
+// It also serves as the next handler,
+// should it end up wrapped by a constructor that does not know about it.
+type buildProbe struct {
+	next     http.Handler
+	index    int
+	total    int
+	buildCtx interface{}
+}
+
+func (p *buildProbe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.next.ServeHTTP(w, r)
+}
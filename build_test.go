@@ -0,0 +1,63 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testPool struct {
+	users []string
+}
+
+// A build context constructor for middleware
+// that registers itself in the shared pool and writes its tag.
+func poolMiddleware(tag string) Constructor {
+	return WithBuildContext(func(h http.Handler, buildCtx interface{}) http.Handler {
+		if pool, ok := buildCtx.(*testPool); ok {
+			pool.users = append(pool.users, tag)
+		}
+		return tagMiddleware(tag + "\n")(h)
+	})
+}
+
+func TestThenWithSharesBuildContext(t *testing.T) {
+	pool := &testPool{}
+	chain := New(poolMiddleware("t1"), tagMiddleware("t2\n"), Named("t3", poolMiddleware("t3")))
+
+	chained := chain.ThenWith(pool, testApp)
+
+	if len(pool.users) != 2 || pool.users[0] != "t3" || pool.users[1] != "t1" {
+		t.Errorf("ThenWith does not share the build context correctly: %v", pool.users)
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("ThenWith does not order handlers correctly")
+	}
+}
+
+func TestWithBuildContextOutsideThenWith(t *testing.T) {
+	calls := 0
+	c := WithBuildContext(func(h http.Handler, buildCtx interface{}) http.Handler {
+		calls++
+		if buildCtx != nil {
+			t.Errorf("WithBuildContext should receive a nil build context outside ThenWith, got %v", buildCtx)
+		}
+		return h
+	})
+
+	New(c).Then(testApp)
+	c(testApp)
+
+	if calls != 2 {
+		t.Errorf("WithBuildContext should be called twice, was called %d times", calls)
+	}
+}
@@ -0,0 +1,34 @@
+package alice
+
+// Builder accumulates constructors in place,
+// for building a chain step by step,
+// for instance from configuration,
+// without allocating a new chain at every step.
+//
+// The zero value is an empty builder ready to use.
+// Unlike Chain, a Builder is mutable
+// and must not be used concurrently.
+//
+//	var b alice.Builder
+//	for _, name := range names {
+//		b.Add(middleware[name])
+//	}
+//	chain := b.Chain()
+type Builder struct {
+	constructors []Constructor
+}
+
+// Add adds the specified constructors
+// as the last ones in the request flow,
+// and returns the builder to allow chaining calls.
+func (b *Builder) Add(constructors ...Constructor) *Builder {
+	b.constructors = append(b.constructors, constructors...)
+	return b
+}
+
+// Chain creates a new chain from the constructors added so far.
+// The chain does not share any storage with the builder:
+// later calls to Add do not affect it.
+func (b *Builder) Chain() Chain {
+	return New(b.constructors...)
+}
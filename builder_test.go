@@ -0,0 +1,43 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBuilderBuildsChainCorrectly(t *testing.T) {
+	var b Builder
+	b.Add(tagMiddleware("t1\n")).Add(tagMiddleware("t2\n"), tagMiddleware("t3\n"))
+	chain := b.Chain()
+
+	if len(chain.constructors) != 3 {
+		t.Error("chain should have 3 constructors")
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("Builder does not build the chain correctly")
+	}
+}
+
+func TestBuilderChainRespectsImmutability(t *testing.T) {
+	var b Builder
+	b.Add(tagMiddleware(""))
+	chain := b.Chain()
+	b.Add(tagMiddleware(""))
+
+	if len(chain.constructors) != 1 {
+		t.Error("Add should not affect chains already built")
+	}
+	if &chain.constructors[0] == &b.constructors[0] {
+		t.Error("Chain does not respect immutability")
+	}
+}
@@ -1,19 +1,60 @@
 // Package alice provides a convenient way to chain http handlers.
 package alice
 
-import "net/http"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
 // A constructor for a piece of middleware.
 // Some middleware use this constructor out of the box,
 // so in most cases you can just pass somepackage.New
 type Constructor func(http.Handler) http.Handler
 
+// Identity is a constructor for middleware that does nothing:
+// it returns the next handler as is.
+// It stands in for optional middleware,
+// sparing nil checks when assembling chains.
+//
+//	compress := alice.Constructor(alice.Identity)
+//	if cfg.Gzip {
+//		compress = gzipHandler
+//	}
+//	alice.New(logger, compress).Then(h)
+func Identity(h http.Handler) http.Handler {
+	return h
+}
+
 // Chain acts as a list of http.Handler constructors.
 // Chain is effectively immutable:
 // once created, it will always hold
 // the same set of constructors in the same order.
+//
+// A chain is safe for concurrent use:
+// Then() and the other methods can be called from several goroutines,
+// provided the constructors themselves can.
+// Handlers built from a chain are as safe for concurrent use
+// as the middleware they are made of.
 type Chain struct {
 	constructors []Constructor
+	reversed     bool
+	recovering   bool
+	fallback     http.Handler
+	compiled     *compileCache
+}
+
+// compileCache holds the handlers built by Compile,
+// keyed by the handler they were built around.
+type compileCache struct {
+	mu       sync.Mutex
+	handlers map[http.Handler]http.Handler
 }
 
 // New creates a new chain,
@@ -21,7 +62,10 @@ type Chain struct {
 // New serves no other function,
 // constructors are only called upon a call to Then().
 func New(constructors ...Constructor) Chain {
-	return Chain{append(([]Constructor)(nil), constructors...)}
+	return Chain{
+		constructors: append(([]Constructor)(nil), constructors...),
+		compiled:     &compileCache{},
+	}
 }
 
 // Then chains the middleware and returns the final http.Handler.
@@ -41,17 +85,21 @@ func New(constructors ...Constructor) Chain {
 // when a chain is reused in this way.
 // For proper middleware, this should cause no problems.
 //
-// Then() treats nil as http.DefaultServeMux.
+// Then() treats nil as http.DefaultServeMux,
+// or as the handler given to WithDefaultHandler().
+//
+// If the chain was obtained through Reversed(),
+// the wrapping order is inverted, making the last constructor the outermost.
 func (c Chain) Then(h http.Handler) http.Handler {
 	if h == nil {
-		h = http.DefaultServeMux
+		h = c.defaultHandler()
 	}
 
 	for i := range c.constructors {
-		h = c.constructors[len(c.constructors)-1-i](h)
+		h = c.build(i, h, nil)
 	}
 
-	return h
+	return c.outermost(h)
 }
 
 // ThenFunc works identically to Then, but takes
@@ -69,6 +117,126 @@ func (c Chain) ThenFunc(fn http.HandlerFunc) http.Handler {
 	return c.Then(fn)
 }
 
+// ThenFuncs works like ThenFunc, but takes several HandlerFuncs
+// which are called one after the other, in order,
+// once the request has gone through the middleware.
+//
+//	New(m1, m2).ThenFuncs(fn1, fn2)
+//	// requests go m1 -> m2 -> fn1, then fn2
+//
+// ThenFuncs without any HandlerFunc behaves like ThenFunc(nil).
+func (c Chain) ThenFuncs(fns ...http.HandlerFunc) http.Handler {
+	if len(fns) == 0 {
+		return c.Then(nil)
+	}
+
+	fns = append(([]http.HandlerFunc)(nil), fns...)
+	return c.Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, fn := range fns {
+			fn(w, r)
+		}
+	}))
+}
+
+// ThenOrElse works like Then,
+// but returns fallback instead if a constructor panics
+// while building the chain,
+// letting non-critical routes degrade gracefully
+// rather than take the program down.
+//
+//	h := stdChain.ThenOrElse(app, http.NotFoundHandler())
+func (c Chain) ThenOrElse(h, fallback http.Handler) (built http.Handler) {
+	defer func() {
+		if recover() != nil {
+			built = fallback
+		}
+	}()
+
+	return c.Then(h)
+}
+
+// ThenContext works identically to Then,
+// but checks ctx before calling each constructor
+// and stops building as soon as ctx is done,
+// returning ctx.Err().
+//
+// ctx only bounds the building of the chain:
+// it has no effect on the requests served by the returned handler.
+func (c Chain) ThenContext(ctx context.Context, h http.Handler) (http.Handler, error) {
+	if h == nil {
+		h = c.defaultHandler()
+	}
+
+	for i := range c.constructors {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		h = c.build(i, h, nil)
+	}
+
+	return c.outermost(h), nil
+}
+
+// ThenWith works like Then,
+// but hands buildCtx to the constructors created by WithBuildContext,
+// letting constructors share state while the chain is built,
+// such as a connection pool.
+// Other constructors are called as Then would call them.
+//
+//	pool := newPool()
+//	h := alice.New(alice.WithBuildContext(cache), alice.WithBuildContext(sessions)).ThenWith(pool, app)
+func (c Chain) ThenWith(buildCtx interface{}, h http.Handler) http.Handler {
+	if h == nil {
+		h = c.defaultHandler()
+	}
+
+	for i := range c.constructors {
+		h = c.build(i, h, buildCtx)
+	}
+
+	return c.outermost(h)
+}
+
+// ThenTimed works like Then,
+// but also returns the time spent building the chain,
+// to help find slow constructors at startup.
+func (c Chain) ThenTimed(h http.Handler) (http.Handler, time.Duration) {
+	start := time.Now()
+	h = c.Then(h)
+
+	return h, time.Since(start)
+}
+
+// ThenLayers works like Then, but returns every handler
+// produced while building the chain,
+// from the outermost one, which Then would return,
+// to the given handler itself:
+//
+//	New(m1, m2).ThenLayers(h)
+//	// []http.Handler{m1(m2(h)), m2(h), h}
+//
+// If the chain was made with WithDefaultRecovery(),
+// the recovering handler Then would return comes first, as an extra layer.
+//
+// ThenLayers is meant for debugging and testing middleware.
+func (c Chain) ThenLayers(h http.Handler) []http.Handler {
+	if h == nil {
+		h = c.defaultHandler()
+	}
+
+	layers := make([]http.Handler, len(c.constructors)+1)
+	layers[len(c.constructors)] = h
+	for i := range c.constructors {
+		h = c.build(i, h, nil)
+		layers[len(c.constructors)-1-i] = h
+	}
+	if c.recovering {
+		layers = append([]http.Handler{c.outermost(h)}, layers...)
+	}
+
+	return layers
+}
+
 // Append extends a chain, adding the specified constructors
 // as the last ones in the request flow.
 //
@@ -83,7 +251,7 @@ func (c Chain) Append(constructors ...Constructor) Chain {
 	newCons = append(newCons, c.constructors...)
 	newCons = append(newCons, constructors...)
 
-	return Chain{newCons}
+	return c.derive(newCons)
 }
 
 // Extend extends a chain by adding the specified chain
@@ -110,3 +278,796 @@ func (c Chain) Append(constructors ...Constructor) Chain {
 func (c Chain) Extend(chain Chain) Chain {
 	return c.Append(chain.constructors...)
 }
+
+// AppendMixed works like Append and Extend together:
+// it adds the given items as the last ones in the request flow,
+// in order, each item being either a Constructor,
+// a func(http.Handler) http.Handler or a Chain,
+// whose constructors are added in their own order.
+//
+//	extChain, err := stdChain.AppendMixed(m1, authChain, m2)
+//	// requests in extChain go through stdChain, then m1 -> authChain -> m2
+//
+// AppendMixed returns an error, and no chain,
+// if an item is of any other type.
+func (c Chain) AppendMixed(items ...interface{}) (Chain, error) {
+	var newCons []Constructor
+	for i, item := range items {
+		switch item := item.(type) {
+		case Constructor:
+			newCons = append(newCons, item)
+		case func(http.Handler) http.Handler:
+			newCons = append(newCons, item)
+		case Chain:
+			newCons = append(newCons, item.constructors...)
+		default:
+			return Chain{}, fmt.Errorf("alice: item #%d: unsupported type %T", i, item)
+		}
+	}
+
+	return c.Append(newCons...), nil
+}
+
+// Prepend extends a chain, adding the specified constructors
+// as the first ones in the request flow.
+//
+// Prepend returns a new chain, leaving the original one untouched.
+//
+//	stdChain := alice.New(m1, m2)
+//	extChain := stdChain.Prepend(m3, m4)
+//	// requests in stdChain go m1 -> m2
+//	// requests in extChain go m3 -> m4 -> m1 -> m2
+func (c Chain) Prepend(constructors ...Constructor) Chain {
+	newCons := make([]Constructor, 0, len(c.constructors)+len(constructors))
+	newCons = append(newCons, constructors...)
+	newCons = append(newCons, c.constructors...)
+
+	return c.derive(newCons)
+}
+
+// Insert returns a new chain with the specified constructors
+// inserted at the given index, shifting the constructors
+// previously at and after that index further down the request flow.
+//
+// An index of 0 behaves like Prepend, an index of Len() like Append.
+// Insert returns an error if index is out of that range.
+// The original chain is left untouched.
+//
+//	stdChain := alice.New(m1, m2)
+//	extChain, _ := stdChain.Insert(1, m3)
+//	// requests in extChain go m1 -> m3 -> m2
+func (c Chain) Insert(index int, constructors ...Constructor) (Chain, error) {
+	if index < 0 || index > len(c.constructors) {
+		return Chain{}, fmt.Errorf("alice: insert index %d out of range [0, %d]", index, len(c.constructors))
+	}
+
+	newCons := make([]Constructor, 0, len(c.constructors)+len(constructors))
+	newCons = append(newCons, c.constructors[:index]...)
+	newCons = append(newCons, constructors...)
+	newCons = append(newCons, c.constructors[index:]...)
+
+	return c.derive(newCons), nil
+}
+
+// RemoveAt returns a new chain without the constructor at the given index.
+// It returns an error if index is out of range.
+// The original chain is left untouched.
+//
+//	stdChain := alice.New(m1, m2, m3)
+//	pubChain, _ := stdChain.RemoveAt(1)
+//	// requests in stdChain go m1 -> m2 -> m3
+//	// requests in pubChain go m1 -> m3
+func (c Chain) RemoveAt(index int) (Chain, error) {
+	if index < 0 || index >= len(c.constructors) {
+		return Chain{}, fmt.Errorf("alice: remove index %d out of range [0, %d)", index, len(c.constructors))
+	}
+
+	newCons := make([]Constructor, 0, len(c.constructors)-1)
+	newCons = append(newCons, c.constructors[:index]...)
+	newCons = append(newCons, c.constructors[index+1:]...)
+
+	return c.derive(newCons), nil
+}
+
+// Replace returns a new chain with the constructor at the given index
+// swapped for the specified one.
+// It returns an error if index is out of range.
+// The original chain is left untouched.
+//
+//	stdChain := alice.New(m1, stub, m3)
+//	realChain, _ := stdChain.Replace(1, m2)
+//	// requests in realChain go m1 -> m2 -> m3
+func (c Chain) Replace(index int, constructor Constructor) (Chain, error) {
+	if index < 0 || index >= len(c.constructors) {
+		return Chain{}, fmt.Errorf("alice: replace index %d out of range [0, %d)", index, len(c.constructors))
+	}
+
+	newCons := make([]Constructor, len(c.constructors))
+	copy(newCons, c.constructors)
+	newCons[index] = constructor
+
+	return c.derive(newCons), nil
+}
+
+// Reverse returns a new chain holding the same constructors
+// in the opposite order.
+// The original chain is left untouched.
+//
+//	reqChain := alice.New(m1, m2, m3)
+//	respChain := reqChain.Reverse()
+//	// requests in respChain go m3 -> m2 -> m1
+func (c Chain) Reverse() Chain {
+	newCons := make([]Constructor, len(c.constructors))
+	for i, constructor := range c.constructors {
+		newCons[len(c.constructors)-1-i] = constructor
+	}
+
+	return c.derive(newCons)
+}
+
+// Len returns the number of constructors in the chain.
+func (c Chain) Len() int {
+	return len(c.constructors)
+}
+
+// IsEmpty reports whether the chain holds no constructors.
+func (c Chain) IsEmpty() bool {
+	return len(c.constructors) == 0
+}
+
+// Constructors returns the constructors of the chain
+// in the order requests flow through them.
+//
+// The returned slice is a copy:
+// modifying it does not affect the chain.
+func (c Chain) Constructors() []Constructor {
+	return append(([]Constructor)(nil), c.constructors...)
+}
+
+// Clone returns a new chain holding the same constructors
+// in the same order, backed by its own storage.
+//
+//	base := alice.New(m1, m2)
+//	fork := base.Clone()
+func (c Chain) Clone() Chain {
+	return c.derive(append(([]Constructor)(nil), c.constructors...))
+}
+
+// Reversed returns a new chain holding the same constructors
+// that Then() wraps in the opposite order:
+// the last constructor becomes the outermost one.
+//
+//	alice.New(m1, m2, m3).Reversed().Then(h)
+//	// is equivalent to:
+//	m3(m2(m1(h)))
+//
+// The setting is kept by chains derived through Append(), Extend() and friends.
+// Unlike Reverse(), the order of the constructors themselves is unchanged.
+func (c Chain) Reversed() Chain {
+	newChain := c.Clone()
+	newChain.reversed = true
+
+	return newChain
+}
+
+// WithDefaultRecovery returns a new chain whose built handlers
+// recover from any panic in the middleware or the final handler,
+// responding 500 Internal Server Error,
+// as if Recover(nil) were the outermost constructor.
+//
+// The setting is kept by chains derived through Append(), Extend() and friends.
+func (c Chain) WithDefaultRecovery() Chain {
+	newChain := c.Clone()
+	newChain.recovering = true
+
+	return newChain
+}
+
+// WithDefaultHandler returns a new chain
+// that Then() and its variants build around h when given nil,
+// instead of http.DefaultServeMux.
+// A nil h restores the use of http.DefaultServeMux.
+//
+// The setting is kept by chains derived through Append(), Extend() and friends.
+//
+//	mux := http.NewServeMux()
+//	chain := alice.New(m1, m2).WithDefaultHandler(mux)
+//	chain.Then(nil) // m1(m2(mux))
+func (c Chain) WithDefaultHandler(h http.Handler) Chain {
+	newChain := c.Clone()
+	newChain.fallback = h
+
+	return newChain
+}
+
+// defaultHandler returns the handler chains are built around
+// when given nil.
+func (c Chain) defaultHandler() http.Handler {
+	if c.fallback != nil {
+		return c.fallback
+	}
+
+	return http.DefaultServeMux
+}
+
+// outermost wraps h, the fully built chain,
+// in the handlers required by the chain settings.
+func (c Chain) outermost(h http.Handler) http.Handler {
+	if c.recovering {
+		h = Recover(nil)(h)
+	}
+
+	return h
+}
+
+// buildIndex returns the index of the i-th constructor to be called
+// when building the chain, the innermost one being called first.
+func (c Chain) buildIndex(i int) int {
+	if c.reversed {
+		return i
+	}
+	return len(c.constructors) - 1 - i
+}
+
+// build calls the i-th constructor to be called when building the chain
+// around h, letting constructors created by Indexed and WithBuildContext
+// know their position in the request flow and the build context.
+func (c Chain) build(i int, h http.Handler, buildCtx interface{}) http.Handler {
+	constructor := c.constructors[c.buildIndex(i)]
+	if !acceptsBuildProbe(constructor) {
+		return constructor(h)
+	}
+
+	return constructor(&buildProbe{
+		next:     h,
+		index:    len(c.constructors) - 1 - i,
+		total:    len(c.constructors),
+		buildCtx: buildCtx,
+	})
+}
+
+// derive returns a chain holding the given constructors
+// and the same settings as c.
+func (c Chain) derive(constructors []Constructor) Chain {
+	c.constructors = constructors
+	c.compiled = &compileCache{}
+	return c
+}
+
+// Filter returns a new chain holding only the constructors
+// for which keep returns true, in their original order.
+// The original chain is left untouched.
+func (c Chain) Filter(keep func(Constructor) bool) Chain {
+	newCons := make([]Constructor, 0, len(c.constructors))
+	for _, constructor := range c.constructors {
+		if keep(constructor) {
+			newCons = append(newCons, constructor)
+		}
+	}
+
+	return c.derive(newCons)
+}
+
+// Map returns a new chain where each constructor
+// has been replaced by the result of passing it to transform,
+// in the original order.
+// The original chain is left untouched.
+//
+//	timed := stdChain.Map(func(c alice.Constructor) alice.Constructor {
+//		return func(h http.Handler) http.Handler {
+//			return timingHandler(c(h))
+//		}
+//	})
+func (c Chain) Map(transform func(Constructor) Constructor) Chain {
+	newCons := make([]Constructor, len(c.constructors))
+	for i, constructor := range c.constructors {
+		newCons[i] = transform(constructor)
+	}
+
+	return c.derive(newCons)
+}
+
+// Handler is an alias for Then.
+//
+// The returned handler is built once, when Handler is called,
+// and can be reused and served concurrently
+// as long as the middleware it is made of can be.
+func (c Chain) Handler(app http.Handler) http.Handler {
+	return c.Then(app)
+}
+
+// Compile works like Then, but memorizes the built handler:
+// subsequent calls with the same handler return the handler
+// built by the first call, without calling the constructors again.
+//
+// Handlers are told apart by interface equality,
+// so only pointer handlers, such as *http.ServeMux, are cached:
+// values of other types may hold funcs, which cannot be compared.
+// Others, such as http.HandlerFunc, are built on every call,
+// exactly like Then would.
+//
+// Chains derived from c, for instance through Append(),
+// do not share its cache.
+func (c Chain) Compile(app http.Handler) http.Handler {
+	if c.compiled == nil || (app != nil && reflect.TypeOf(app).Kind() != reflect.Ptr) {
+		return c.Then(app)
+	}
+
+	c.compiled.mu.Lock()
+	defer c.compiled.mu.Unlock()
+
+	if h, ok := c.compiled.handlers[app]; ok {
+		return h
+	}
+
+	h := c.Then(app)
+	if c.compiled.handlers == nil {
+		c.compiled.handlers = make(map[http.Handler]http.Handler)
+	}
+	c.compiled.handlers[app] = h
+
+	return h
+}
+
+// String describes the chain for debugging purposes,
+// listing its constructors in order.
+// Constructors created by Named are shown by name,
+// others by their position:
+//
+//	alice.New(alice.Named("logger", m1), m2).String()
+//	// Chain[2]{logger, #1}
+func (c Chain) String() string {
+	names := make([]string, len(c.constructors))
+	for i, constructor := range c.constructors {
+		if name, ok := Name(constructor); ok {
+			names[i] = name
+		} else {
+			names[i] = "#" + strconv.Itoa(i)
+		}
+	}
+
+	return "Chain[" + strconv.Itoa(len(names)) + "]{" + strings.Join(names, ", ") + "}"
+}
+
+// Validate calls every constructor of the chain, in order,
+// around a handler that does nothing, and discards the result.
+// It returns an error describing the first constructor that panicked,
+// along with its index, or nil if none did.
+//
+// Validate lets programs detect broken middleware at startup,
+// before the chain is actually used.
+// Constructors are called exactly as Then would call them,
+// so any side effect they have when building will happen.
+func (c Chain) Validate() error {
+	for i, constructor := range c.constructors {
+		if err := dryRun(constructor); err != nil {
+			return fmt.Errorf("alice: constructor #%d: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateAll works like Validate,
+// but calls every constructor of the chain even if some of them panic,
+// and returns an error joining those describing each panicking constructor,
+// or nil if none did.
+func (c Chain) ValidateAll() error {
+	var errs []error
+	for i, constructor := range c.constructors {
+		if err := dryRun(constructor); err != nil {
+			errs = append(errs, fmt.Errorf("alice: constructor #%d: %v", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// noopHandler is passed to constructors when dry-running them.
+var noopHandler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+// dryRun calls constructor around noopHandler,
+// turning any panic into an error.
+func dryRun(constructor Constructor) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+
+	constructor(noopHandler)
+
+	return nil
+}
+
+// ExtendFront extends a chain by adding the specified chain
+// as the first one in the request flow.
+//
+// ExtendFront returns a new chain, leaving the original one untouched.
+//
+//	stdChain := alice.New(m1, m2)
+//	preChain := alice.New(m3, m4)
+//	extChain := stdChain.ExtendFront(preChain)
+//	// requests in extChain go m3 -> m4 -> m1 -> m2
+func (c Chain) ExtendFront(chain Chain) Chain {
+	return c.Prepend(chain.constructors...)
+}
+
+// Compose creates a new chain holding the constructors
+// of all the given chains, in order.
+// The given chains are left untouched.
+//
+//	a := alice.New(m1, m2)
+//	b := alice.New(m3)
+//	c := alice.New(m4)
+//	// requests in alice.Compose(a, b, c) go m1 -> m2 -> m3 -> m4
+func Compose(chains ...Chain) Chain {
+	n := 0
+	for _, chain := range chains {
+		n += len(chain.constructors)
+	}
+
+	newCons := make([]Constructor, 0, n)
+	for _, chain := range chains {
+		newCons = append(newCons, chain.constructors...)
+	}
+
+	return New(newCons...)
+}
+
+// Swap returns a new chain with the constructors
+// at indices i and j exchanged.
+// It returns an error if either index is out of range.
+// The original chain is left untouched.
+//
+//	stdChain := alice.New(m1, m2, m3)
+//	newChain, _ := stdChain.Swap(0, 2)
+//	// requests in newChain go m3 -> m2 -> m1
+func (c Chain) Swap(i, j int) (Chain, error) {
+	for _, index := range []int{i, j} {
+		if index < 0 || index >= len(c.constructors) {
+			return Chain{}, fmt.Errorf("alice: swap index %d out of range [0, %d)", index, len(c.constructors))
+		}
+	}
+
+	newCons := make([]Constructor, len(c.constructors))
+	copy(newCons, c.constructors)
+	newCons[i], newCons[j] = newCons[j], newCons[i]
+
+	return c.derive(newCons), nil
+}
+
+// Truncate returns a new chain holding only the first n constructors.
+// It returns an error if n is negative or greater than Len().
+// The original chain is left untouched.
+//
+//	fullChain := alice.New(m1, m2, m3)
+//	newChain, _ := fullChain.Truncate(2)
+//	// requests in newChain go m1 -> m2
+func (c Chain) Truncate(n int) (Chain, error) {
+	if n < 0 || n > len(c.constructors) {
+		return Chain{}, fmt.Errorf("alice: truncate length %d out of range [0, %d]", n, len(c.constructors))
+	}
+
+	return c.derive(append(([]Constructor)(nil), c.constructors[:n]...)), nil
+}
+
+// Sub returns a new chain holding the constructors
+// in the half-open range [start, end).
+// It returns an error if the bounds are negative,
+// reversed or greater than Len().
+// The original chain is left untouched.
+//
+//	fullChain := alice.New(m1, m2, m3, m4)
+//	newChain, _ := fullChain.Sub(1, 3)
+//	// requests in newChain go m2 -> m3
+func (c Chain) Sub(start, end int) (Chain, error) {
+	if start < 0 || start > end || end > len(c.constructors) {
+		return Chain{}, fmt.Errorf("alice: sub range [%d, %d) out of bounds for length %d", start, end, len(c.constructors))
+	}
+
+	return c.derive(append(([]Constructor)(nil), c.constructors[start:end]...)), nil
+}
+
+// InsertBefore returns a new chain with the specified constructors
+// inserted right before the first constructor named name with Named.
+// It returns an error if the chain holds no such constructor.
+// The original chain is left untouched.
+//
+//	stdChain := alice.New(logger, alice.Named("router", router))
+//	newChain, _ := stdChain.InsertBefore("router", csrf)
+//	// requests in newChain go logger -> csrf -> router
+func (c Chain) InsertBefore(name string, constructors ...Constructor) (Chain, error) {
+	index := c.indexOfName(name)
+	if index < 0 {
+		return Chain{}, fmt.Errorf("alice: no constructor named %q", name)
+	}
+
+	return c.Insert(index, constructors...)
+}
+
+// InsertAfter returns a new chain with the specified constructors
+// inserted right after the first constructor named name with Named.
+// It returns an error if the chain holds no such constructor.
+// The original chain is left untouched.
+//
+//	stdChain := alice.New(alice.Named("logger", logger), router)
+//	newChain, _ := stdChain.InsertAfter("logger", gzip)
+//	// requests in newChain go logger -> gzip -> router
+func (c Chain) InsertAfter(name string, constructors ...Constructor) (Chain, error) {
+	index := c.indexOfName(name)
+	if index < 0 {
+		return Chain{}, fmt.Errorf("alice: no constructor named %q", name)
+	}
+
+	return c.Insert(index+1, constructors...)
+}
+
+// RemoveByName returns a new chain without the first constructor
+// named name with Named.
+// It returns an error if the chain holds no such constructor.
+// The original chain is left untouched.
+//
+// To remove every constructor with a given name, use Filter.
+//
+//	stdChain := alice.New(logger, alice.Named("auth", auth), router)
+//	pubChain, _ := stdChain.RemoveByName("auth")
+//	// requests in pubChain go logger -> router
+func (c Chain) RemoveByName(name string) (Chain, error) {
+	index := c.indexOfName(name)
+	if index < 0 {
+		return Chain{}, fmt.Errorf("alice: no constructor named %q", name)
+	}
+
+	return c.RemoveAt(index)
+}
+
+// ReplaceByName returns a new chain with the first constructor
+// named name with Named swapped for constructor,
+// which is given the same name.
+// It returns an error if the chain holds no such constructor.
+// The original chain is left untouched.
+//
+//	stdChain := alice.New(logger, alice.Named("auth", auth), router)
+//	testChain, _ := stdChain.ReplaceByName("auth", fakeAuth)
+//	// requests in testChain go logger -> fakeAuth -> router
+func (c Chain) ReplaceByName(name string, constructor Constructor) (Chain, error) {
+	index := c.indexOfName(name)
+	if index < 0 {
+		return Chain{}, fmt.Errorf("alice: no constructor named %q", name)
+	}
+
+	return c.Replace(index, Named(name, constructor))
+}
+
+// indexOfName returns the index of the first constructor
+// named name with Named, or -1 if there is none.
+func (c Chain) indexOfName(name string) int {
+	for i, constructor := range c.constructors {
+		if n, ok := Name(constructor); ok && n == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Dedup returns a new chain without the constructors
+// that already appear earlier in the chain,
+// keeping the first occurrence of each in its original position.
+//
+// Constructors are duplicates when they are the same function value,
+// which is the case when chains sharing a constructor are combined,
+// or when they were given the same name with Named.
+// Separately created closures are never duplicates of each other,
+// even when they were created by the same function,
+// such as two calls to SetHeaders.
+// The original chain is left untouched.
+func (c Chain) Dedup() Chain {
+	newCons := make([]Constructor, 0, len(c.constructors))
+	names := make(map[string]bool)
+
+	for _, constructor := range c.constructors {
+		if name, ok := Name(constructor); ok {
+			if names[name] {
+				continue
+			}
+			names[name] = true
+		}
+
+		duplicate := false
+		for _, kept := range newCons {
+			if sameValue(kept, constructor) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			newCons = append(newCons, constructor)
+		}
+	}
+
+	return c.derive(newCons)
+}
+
+// ForEach calls fn for each constructor of the chain,
+// in the order requests flow through them,
+// along with its index.
+//
+// Unlike Constructors, ForEach does not copy the chain.
+func (c Chain) ForEach(fn func(index int, c Constructor)) {
+	for i, constructor := range c.constructors {
+		fn(i, constructor)
+	}
+}
+
+// Count returns the number of constructors of the chain
+// for which pred returns true.
+//
+//	hasAuth := chain.Count(func(c alice.Constructor) bool {
+//		name, _ := alice.Name(c)
+//		return name == "auth"
+//	}) > 0
+func (c Chain) Count(pred func(Constructor) bool) int {
+	n := 0
+	for _, constructor := range c.constructors {
+		if pred(constructor) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// IndexOf returns the index of the first constructor of the chain
+// that is the same function as target, as told by reflect.Value.Pointer,
+// or -1 if there is none.
+//
+// Closures created by the same function literal are the same function,
+// whatever they captured, and so are method values of the same method,
+// such as th.Throttle, whatever their receiver:
+// IndexOf cannot tell them apart.
+// Constructors returned by Named and Prioritized are compared
+// by their annotations and the constructor they wrap,
+// unless they have the same name.
+func (c Chain) IndexOf(target Constructor) int {
+	for i, constructor := range c.constructors {
+		if sameCode(constructor, target) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Constructor returns a constructor applying the whole chain
+// in front of the next handler,
+// so that the chain can be used as a single piece of middleware,
+// for instance within another chain.
+//
+//	group := alice.New(m2, m3)
+//	alice.New(m1, group.Constructor(), m4).Then(h)
+//	// requests go m1 -> m2 -> m3 -> m4 -> h
+//
+// The constructors of the chain are called
+// every time the returned constructor is.
+func (c Chain) Constructor() Constructor {
+	return func(h http.Handler) http.Handler {
+		return c.Then(h)
+	}
+}
+
+// Partial builds the middleware of the chain once,
+// and returns a function attaching terminal handlers to it.
+// The handlers it returns behave like the ones returned by Then,
+// but all of them share the same middleware instances,
+// and attaching a handler does not call any constructor.
+//
+//	attach := stdChain.Partial()
+//	indexPipe := attach(indexHandler)
+//	authPipe := attach(authHandler)
+//
+// The terminal handler travels with the request context,
+// so middleware must pass on a context derived from the one it received,
+// as http.Request.WithContext does;
+// the shared middleware panics otherwise.
+// The attaching function treats nil as Then() does.
+func (c Chain) Partial() func(http.Handler) http.Handler {
+	// Each call gets its own key,
+	// so that the attaching functions of nested Partial calls
+	// do not overwrite each other's terminal handler.
+	key := new(terminalKey)
+
+	built := c.Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := r.Context().Value(key).(http.Handler)
+		if !ok {
+			panic("alice: Partial terminal handler missing from the request context")
+		}
+		h.ServeHTTP(w, r)
+	}))
+
+	return func(h http.Handler) http.Handler {
+		if h == nil {
+			h = c.defaultHandler()
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			built.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), key, h)))
+		})
+	}
+}
+
+// terminalKey is the type of the context keys under which
+// handlers returned by Partial store their terminal handler.
+// It is not zero-sized, so that pointers to distinct keys differ.
+type terminalKey struct{ _ byte }
+
+// Equal reports whether c and other hold the same constructors
+// in the same order, and build them in the same way:
+// in the same order, with the same WithDefaultRecovery()
+// and WithDefaultHandler() settings.
+//
+// Constructors are the same when they are the same function value
+// or when they were given the same name with Named.
+// Separately created closures are never the same,
+// even when they were created by the same function literal:
+//
+//	alice.New(m1).Equal(alice.New(m1)) // true
+//	alice.New(timeout(time.Second)).Equal(alice.New(timeout(time.Second))) // false
+func (c Chain) Equal(other Chain) bool {
+	if len(c.constructors) != len(other.constructors) || c.reversed != other.reversed ||
+		c.recovering != other.recovering || !sameHandler(c.fallback, other.fallback) {
+		return false
+	}
+
+	for i, constructor := range c.constructors {
+		if !sameValue(constructor, other.constructors[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Splice returns a new chain where the constructors
+// in the half-open range [start, end)
+// have been replaced by the specified ones.
+// It returns an error if the bounds are negative,
+// reversed or greater than Len().
+// The original chain is left untouched.
+//
+//	stdChain := alice.New(m1, m2, m3, m4)
+//	newChain, _ := stdChain.Splice(1, 3, m5)
+//	// requests in newChain go m1 -> m5 -> m4
+func (c Chain) Splice(start, end int, replacement ...Constructor) (Chain, error) {
+	if start < 0 || start > end || end > len(c.constructors) {
+		return Chain{}, fmt.Errorf("alice: splice range [%d, %d) out of bounds for length %d", start, end, len(c.constructors))
+	}
+
+	newCons := make([]Constructor, 0, len(c.constructors)-(end-start)+len(replacement))
+	newCons = append(newCons, c.constructors[:start]...)
+	newCons = append(newCons, replacement...)
+	newCons = append(newCons, c.constructors[end:]...)
+
+	return c.derive(newCons), nil
+}
+
+// First returns the first constructor of the chain,
+// the outermost one unless the chain was obtained through Reversed().
+// The boolean is false if the chain is empty.
+func (c Chain) First() (Constructor, bool) {
+	if len(c.constructors) == 0 {
+		return nil, false
+	}
+	return c.constructors[0], true
+}
+
+// Last returns the last constructor of the chain,
+// the innermost one unless the chain was obtained through Reversed().
+// The boolean is false if the chain is empty.
+func (c Chain) Last() (Constructor, bool) {
+	if len(c.constructors) == 0 {
+		return nil, false
+	}
+	return c.constructors[len(c.constructors)-1], true
+}
@@ -2,10 +2,15 @@
 package alice
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // A constructor for middleware
@@ -28,6 +33,15 @@ func funcsEqual(f1, f2 interface{}) bool {
 	return val1.Pointer() == val2.Pointer()
 }
 
+// A constructor for middleware
+// that counts how many times it has been called.
+func countingMiddleware(count *int) Constructor {
+	return func(h http.Handler) http.Handler {
+		*count++
+		return h
+	}
+}
+
 var testApp = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("app\n"))
 })
@@ -69,6 +83,32 @@ func TestThenFuncTreatsNilAsDefaultServeMux(t *testing.T) {
 	}
 }
 
+func TestWithDefaultHandlerReplacesDefaultServeMux(t *testing.T) {
+	mux := http.NewServeMux()
+	chain := New().WithDefaultHandler(mux)
+
+	if chain.Then(nil) != mux || chain.ThenFunc(nil) != mux {
+		t.Error("WithDefaultHandler does not replace DefaultServeMux")
+	}
+	if New().Then(nil) != http.DefaultServeMux {
+		t.Error("WithDefaultHandler modifies other chains")
+	}
+	if chain.WithDefaultHandler(nil).Then(nil) != http.DefaultServeMux {
+		t.Error("WithDefaultHandler(nil) does not restore DefaultServeMux")
+	}
+}
+
+func TestWithDefaultHandlerIsKeptByAppend(t *testing.T) {
+	chained := New().WithDefaultHandler(testApp).Append(tagMiddleware("t1\n")).Then(nil)
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Body.String() != "t1\napp\n" {
+		t.Error("Append does not keep the WithDefaultHandler setting")
+	}
+}
+
 func TestThenFuncConstructsHandlerFunc(t *testing.T) {
 	fn := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
@@ -176,3 +216,1518 @@ func TestExtendRespectsImmutability(t *testing.T) {
 		t.Error("Extend does not respect immutability")
 	}
 }
+
+func TestAppendMixedFlattensItems(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"))
+	newChain, err := chain.AppendMixed(
+		tagMiddleware("t2\n"),
+		New(tagMiddleware("t3\n"), tagMiddleware("t4\n")),
+		t2Middleware,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Body.String() != "t1\nt2\nt3\nt4\nt2\napp\n" {
+		t.Errorf("AppendMixed does not flatten items in order: %q", w.Body.String())
+	}
+	if chain.Len() != 1 {
+		t.Error("AppendMixed modifies the original chain")
+	}
+}
+
+func TestAppendMixedRejectsOtherTypes(t *testing.T) {
+	_, err := New().AppendMixed(tagMiddleware(""), "t2")
+	if err == nil || err.Error() != "alice: item #1: unsupported type string" {
+		t.Errorf("AppendMixed returned %v", err)
+	}
+}
+
+func TestPrependAddsHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t3\n"), tagMiddleware("t4\n"))
+	newChain := chain.Prepend(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+
+	if len(chain.constructors) != 2 {
+		t.Error("chain should have 2 constructors")
+	}
+	if len(newChain.constructors) != 4 {
+		t.Error("newChain should have 4 constructors")
+	}
+
+	chained := newChain.Then(testApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nt3\nt4\napp\n" {
+		t.Error("Prepend does not add handlers correctly")
+	}
+}
+
+func TestPrependRespectsImmutability(t *testing.T) {
+	chain := New(tagMiddleware(""))
+	newChain := chain.Prepend(tagMiddleware(""))
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("Prepend does not respect immutability")
+	}
+}
+
+func TestInsertAddsHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+
+	tests := []struct {
+		index    int
+		expected string
+	}{
+		{0, "t3\nt1\nt2\napp\n"},
+		{1, "t1\nt3\nt2\napp\n"},
+		{2, "t1\nt2\nt3\napp\n"},
+	}
+
+	for _, test := range tests {
+		newChain, err := chain.Insert(test.index, tagMiddleware("t3\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chain.constructors) != 2 {
+			t.Error("chain should have 2 constructors")
+		}
+		if len(newChain.constructors) != 3 {
+			t.Error("newChain should have 3 constructors")
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newChain.Then(testApp).ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("Insert at %d does not add handlers correctly: %q", test.index, w.Body.String())
+		}
+	}
+}
+
+func TestInsertRejectsOutOfRangeIndex(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+
+	for _, index := range []int{-1, 3} {
+		if _, err := chain.Insert(index, tagMiddleware("")); err == nil {
+			t.Errorf("Insert at %d should return an error", index)
+		}
+	}
+}
+
+func TestInsertRespectsImmutability(t *testing.T) {
+	chain := New(tagMiddleware(""))
+	newChain, err := chain.Insert(1, tagMiddleware(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("Insert does not respect immutability")
+	}
+}
+
+func TestRemoveAtRemovesHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"), tagMiddleware("t3\n"))
+	newChain, err := chain.RemoveAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chain.constructors) != 3 {
+		t.Error("chain should have 3 constructors")
+	}
+	if len(newChain.constructors) != 2 {
+		t.Error("newChain should have 2 constructors")
+	}
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("RemoveAt modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt3\napp\n" {
+		t.Error("RemoveAt does not remove handlers correctly")
+	}
+}
+
+func TestRemoveAtRejectsOutOfRangeIndex(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+
+	for _, index := range []int{-1, 2} {
+		if _, err := chain.RemoveAt(index); err == nil {
+			t.Errorf("RemoveAt %d should return an error", index)
+		}
+	}
+}
+
+func TestRemoveAtRespectsImmutability(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+	newChain, err := chain.RemoveAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("RemoveAt does not respect immutability")
+	}
+}
+
+func TestReplaceReplacesHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("stub\n"), tagMiddleware("t3\n"))
+	newChain, err := chain.Replace(1, tagMiddleware("t2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nstub\nt3\napp\n" {
+		t.Error("Replace modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("Replace does not replace handlers correctly")
+	}
+}
+
+func TestReplaceRejectsOutOfRangeIndex(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+
+	for _, index := range []int{-1, 2} {
+		if _, err := chain.Replace(index, tagMiddleware("")); err == nil {
+			t.Errorf("Replace at %d should return an error", index)
+		}
+	}
+}
+
+func TestReverseOrdersHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"), tagMiddleware("t3\n"))
+	newChain := chain.Reverse()
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("Reverse modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t3\nt2\nt1\napp\n" {
+		t.Error("Reverse does not reverse handlers correctly")
+	}
+}
+
+func TestLen(t *testing.T) {
+	if New().Len() != 0 {
+		t.Error("Len of an empty chain should be 0")
+	}
+	if New(tagMiddleware(""), tagMiddleware("")).Len() != 2 {
+		t.Error("Len does not count constructors correctly")
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	if !New().IsEmpty() {
+		t.Error("IsEmpty should be true for an empty chain")
+	}
+	if New(tagMiddleware("")).IsEmpty() {
+		t.Error("IsEmpty should be false for a non-empty chain")
+	}
+}
+
+func TestConstructorsReturnsCopy(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+
+	constructors := chain.Constructors()
+	if len(constructors) != 2 {
+		t.Fatal("Constructors should return 2 constructors")
+	}
+	for k := range constructors {
+		if !funcsEqual(constructors[k], chain.constructors[k]) {
+			t.Error("Constructors does not return constructors correctly")
+		}
+	}
+
+	constructors[0] = tagMiddleware("t3\n")
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\napp\n" {
+		t.Error("Constructors does not return a copy")
+	}
+}
+
+func TestCloneCopiesHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+	newChain := chain.Clone()
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("Clone does not respect immutability")
+	}
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w1 := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w1, r)
+	w2 := httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w2, r)
+
+	if w1.Body.String() != w2.Body.String() {
+		t.Error("Clone does not copy handlers correctly")
+	}
+}
+
+func TestReversedOrdersHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+	newChain := chain.Reversed().Append(tagMiddleware("t3\n"))
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Append(tagMiddleware("t3\n")).Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("Reversed modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t3\nt2\nt1\napp\n" {
+		t.Error("Reversed does not order handlers correctly")
+	}
+}
+
+func TestReversedIsKeptByExtend(t *testing.T) {
+	chain := New(tagMiddleware("t1\n")).Reversed().Extend(New(tagMiddleware("t2\n")))
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t2\nt1\napp\n" {
+		t.Error("Extend does not keep the Reversed setting")
+	}
+}
+
+func panicMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("middleware")
+	})
+}
+
+func TestWithDefaultRecoveryRecoversAtAnyDepth(t *testing.T) {
+	panicApp := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("app")
+	})
+
+	chains := map[string]http.Handler{
+		"first middleware":  New(panicMiddleware, tagMiddleware("t1\n")).WithDefaultRecovery().Then(testApp),
+		"inner middleware":  New(tagMiddleware("t1\n")).WithDefaultRecovery().Append(panicMiddleware).Then(testApp),
+		"final handler":     New(tagMiddleware("t1\n")).WithDefaultRecovery().Then(panicApp),
+		"reversed chain":    New(panicMiddleware).Reversed().WithDefaultRecovery().Then(testApp),
+		"chain built later": New().WithDefaultRecovery().ThenFunc(panicApp),
+	}
+
+	for name, h := range chains {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		// Middleware writing before the panic has already sent a 200 status.
+		if !strings.HasSuffix(w.Body.String(), http.StatusText(http.StatusInternalServerError)+"\n") {
+			t.Errorf("WithDefaultRecovery does not respond 500 to a panic in the %s", name)
+		}
+	}
+}
+
+func TestWithDefaultRecoveryLeavesOriginalChain(t *testing.T) {
+	chain := New(panicMiddleware)
+	chain.WithDefaultRecovery()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("WithDefaultRecovery modifies the original chain")
+		}
+	}()
+	chain.Then(testApp).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+// Standalone middleware constructors.
+// Unlike the closures returned by tagMiddleware,
+// they can be told apart by pointer identity.
+func t1Middleware(h http.Handler) http.Handler {
+	return tagMiddleware("t1\n")(h)
+}
+
+func t2Middleware(h http.Handler) http.Handler {
+	return tagMiddleware("t2\n")(h)
+}
+
+func t3Middleware(h http.Handler) http.Handler {
+	return tagMiddleware("t3\n")(h)
+}
+
+func TestFilterRemovesHandlersCorrectly(t *testing.T) {
+	t2 := Constructor(t2Middleware)
+	chain := New(tagMiddleware("t1\n"), t2, tagMiddleware("t3\n"))
+	newChain := chain.Filter(func(c Constructor) bool {
+		return !funcsEqual(c, t2)
+	})
+
+	if len(chain.constructors) != 3 {
+		t.Error("chain should have 3 constructors")
+	}
+	if len(newChain.constructors) != 2 {
+		t.Error("newChain should have 2 constructors")
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newChain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt3\napp\n" {
+		t.Error("Filter does not remove handlers correctly")
+	}
+}
+
+func TestMapTransformsHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+	newChain := chain.Map(func(c Constructor) Constructor {
+		return func(h http.Handler) http.Handler {
+			return tagMiddleware("x\n")(c(h))
+		}
+	})
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt2\napp\n" {
+		t.Error("Map modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "x\nt1\nx\nt2\napp\n" {
+		t.Error("Map does not transform handlers correctly")
+	}
+}
+
+func TestHandlerBuildsOnce(t *testing.T) {
+	builds := 0
+	handler := New(countingMiddleware(&builds), tagMiddleware("t1\n")).Handler(testApp)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+
+			if w.Body.String() != "t1\napp\n" {
+				t.Error("Handler does not order handlers correctly")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if builds != 1 {
+		t.Errorf("Handler should build the chain once, built %d times", builds)
+	}
+}
+
+func TestCompileBuildsOnce(t *testing.T) {
+	builds := 0
+	chain := New(countingMiddleware(&builds), func(h http.Handler) http.Handler {
+		return http.TimeoutHandler(h, time.Second, "")
+	})
+	app := http.NewServeMux()
+
+	h1 := chain.Compile(app)
+	h2 := chain.Compile(app)
+
+	if builds != 1 {
+		t.Errorf("Compile should build the chain once, built %d times", builds)
+	}
+	if h1 != h2 {
+		t.Error("Compile does not return the cached handler")
+	}
+
+	chain.Compile(http.NewServeMux())
+	if builds != 2 {
+		t.Error("Compile should build the chain for a different handler")
+	}
+
+	chain.Append().Compile(app)
+	if builds != 3 {
+		t.Error("Compile should not share its cache with derived chains")
+	}
+}
+
+func TestCompileBuildsUncomparableHandlers(t *testing.T) {
+	builds := 0
+	chain := New(countingMiddleware(&builds))
+
+	chain.Compile(testApp)
+	chain.Compile(testApp)
+
+	if builds != 2 {
+		t.Errorf("Compile should build uncomparable handlers on every call, built %d times", builds)
+	}
+}
+
+type wrappedHandler struct {
+	next http.Handler
+}
+
+func (h wrappedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.next.ServeHTTP(w, r)
+}
+
+func TestCompileBuildsStructsHoldingFuncs(t *testing.T) {
+	builds := 0
+	chain := New(countingMiddleware(&builds))
+
+	chain.Compile(wrappedHandler{testApp})
+	chain.Compile(wrappedHandler{testApp})
+
+	if builds != 2 {
+		t.Errorf("Compile should build non-pointer handlers on every call, built %d times", builds)
+	}
+}
+
+func TestStringListsNamesCorrectly(t *testing.T) {
+	chain := New(Named("t1", tagMiddleware("")), tagMiddleware(""), Named("t3", tagMiddleware("")))
+
+	if chain.String() != "Chain[3]{t1, #1, t3}" {
+		t.Errorf("String does not list names correctly: %s", chain)
+	}
+}
+
+func TestStringDescribesEmptyChain(t *testing.T) {
+	if New().String() != "Chain[0]{}" {
+		t.Errorf("String does not describe an empty chain correctly: %s", New())
+	}
+	if (Chain{}).String() != "Chain[0]{}" {
+		t.Errorf("String does not describe a zero chain correctly: %s", Chain{})
+	}
+}
+
+func TestStringListsPositionsCorrectly(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+
+	if chain.String() != "Chain[2]{#0, #1}" {
+		t.Errorf("String does not list positions correctly: %s", chain)
+	}
+}
+
+func TestValidateReportsPanickingConstructor(t *testing.T) {
+	chain := New(tagMiddleware(""), func(h http.Handler) http.Handler {
+		panic("broken")
+	}, tagMiddleware(""))
+
+	err := chain.Validate()
+	if err == nil {
+		t.Fatal("Validate should return an error")
+	}
+	if err.Error() != "alice: constructor #1: panic: broken" {
+		t.Errorf("Validate does not report the failing constructor correctly: %v", err)
+	}
+}
+
+func TestValidateAcceptsWorkingChain(t *testing.T) {
+	if err := New(tagMiddleware(""), tagMiddleware("")).Validate(); err != nil {
+		t.Errorf("Validate should not return an error: %v", err)
+	}
+}
+
+func TestExtendFrontAddsHandlersCorrectly(t *testing.T) {
+	chain1 := New(tagMiddleware("t3\n"), tagMiddleware("t4\n"))
+	chain2 := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+	newChain := chain1.ExtendFront(chain2)
+
+	if len(chain1.constructors) != 2 {
+		t.Error("chain1 should contain 2 constructors")
+	}
+	if len(chain2.constructors) != 2 {
+		t.Error("chain2 should contain 2 constructors")
+	}
+	if len(newChain.constructors) != 4 {
+		t.Error("newChain should contain 4 constructors")
+	}
+
+	chained := newChain.Then(testApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nt3\nt4\napp\n" {
+		t.Error("ExtendFront does not add handlers correctly")
+	}
+}
+
+func TestExtendFrontRespectsImmutability(t *testing.T) {
+	chain := New(tagMiddleware(""))
+	newChain := chain.ExtendFront(New(tagMiddleware("")))
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("ExtendFront does not respect immutability")
+	}
+}
+
+func TestComposeAddsHandlersCorrectly(t *testing.T) {
+	chain1 := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+	chain2 := New(tagMiddleware("t3\n"))
+	chain3 := New(tagMiddleware("t4\n"))
+	newChain := Compose(chain1, chain2, chain3)
+
+	if len(chain1.constructors) != 2 || len(chain2.constructors) != 1 || len(chain3.constructors) != 1 {
+		t.Error("Compose should not modify the given chains")
+	}
+	if len(newChain.constructors) != 4 {
+		t.Error("newChain should contain 4 constructors")
+	}
+	if &chain1.constructors[0] == &newChain.constructors[0] {
+		t.Error("Compose does not respect immutability")
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newChain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nt3\nt4\napp\n" {
+		t.Error("Compose does not add handlers correctly")
+	}
+}
+
+func TestThenContextStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builds := 0
+	chain := New(countingMiddleware(&builds), func(h http.Handler) http.Handler {
+		cancel()
+		return h
+	})
+
+	h, err := chain.ThenContext(ctx, testApp)
+	if err != context.Canceled {
+		t.Errorf("ThenContext should return context.Canceled, got %v", err)
+	}
+	if h != nil {
+		t.Error("ThenContext should not return a handler when cancelled")
+	}
+	if builds != 0 {
+		t.Error("ThenContext should not call constructors after cancellation")
+	}
+}
+
+func TestThenContextOrdersHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+
+	chained, err := chain.ThenContext(context.Background(), testApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\napp\n" {
+		t.Error("ThenContext does not order handlers correctly")
+	}
+}
+
+func TestSwapSwapsHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"), tagMiddleware("t3\n"))
+	newChain, err := chain.Swap(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("Swap modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t3\nt2\nt1\napp\n" {
+		t.Error("Swap does not swap handlers correctly")
+	}
+}
+
+func TestSwapRejectsOutOfRangeIndex(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+
+	for _, indices := range [][2]int{{-1, 0}, {0, 2}} {
+		if _, err := chain.Swap(indices[0], indices[1]); err == nil {
+			t.Errorf("Swap of %v should return an error", indices)
+		}
+	}
+}
+
+func TestTruncateRemovesHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"), tagMiddleware("t3\n"))
+
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{0, "app\n"},
+		{2, "t1\nt2\napp\n"},
+		{3, "t1\nt2\nt3\napp\n"},
+	}
+
+	for _, test := range tests {
+		newChain, err := chain.Truncate(test.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chain.constructors) != 3 {
+			t.Error("chain should have 3 constructors")
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newChain.Then(testApp).ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("Truncate to %d does not remove handlers correctly: %q", test.n, w.Body.String())
+		}
+	}
+}
+
+func TestTruncateRejectsOutOfRangeLength(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+
+	for _, n := range []int{-1, 3} {
+		if _, err := chain.Truncate(n); err == nil {
+			t.Errorf("Truncate to %d should return an error", n)
+		}
+	}
+}
+
+func TestTruncateRespectsImmutability(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+	newChain, err := chain.Truncate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("Truncate does not respect immutability")
+	}
+}
+
+func TestSubExtractsHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"), tagMiddleware("t3\n"), tagMiddleware("t4\n"))
+
+	tests := []struct {
+		start, end int
+		expected   string
+	}{
+		{1, 3, "t2\nt3\napp\n"},
+		{0, 4, "t1\nt2\nt3\nt4\napp\n"},
+		{2, 2, "app\n"},
+	}
+
+	for _, test := range tests {
+		newChain, err := chain.Sub(test.start, test.end)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newChain.Then(testApp).ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("Sub [%d, %d) does not extract handlers correctly: %q", test.start, test.end, w.Body.String())
+		}
+	}
+}
+
+func TestSubRejectsInvalidBounds(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+
+	for _, bounds := range [][2]int{{-1, 1}, {2, 1}, {0, 3}} {
+		if _, err := chain.Sub(bounds[0], bounds[1]); err == nil {
+			t.Errorf("Sub %v should return an error", bounds)
+		}
+	}
+}
+
+func TestSubRespectsImmutability(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+	newChain, err := chain.Sub(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("Sub does not respect immutability")
+	}
+}
+
+func TestInsertBeforeAddsHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), Named("t3", tagMiddleware("t3\n")))
+	newChain, err := chain.InsertBefore("t3", tagMiddleware("t2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chain.constructors) != 2 {
+		t.Error("chain should have 2 constructors")
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newChain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("InsertBefore does not add handlers correctly")
+	}
+}
+
+func TestInsertBeforeRejectsUnknownName(t *testing.T) {
+	chain := New(Named("t1", tagMiddleware("")))
+
+	if _, err := chain.InsertBefore("t2", tagMiddleware("")); err == nil {
+		t.Error("InsertBefore should return an error for an unknown name")
+	}
+}
+
+func TestInsertAfterAddsHandlersCorrectly(t *testing.T) {
+	chain := New(Named("t1", tagMiddleware("t1\n")), Named("t3", tagMiddleware("t3\n")))
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"t1", "t1\nt2\nt3\napp\n"},
+		{"t3", "t1\nt3\nt2\napp\n"},
+	}
+
+	for _, test := range tests {
+		newChain, err := chain.InsertAfter(test.name, tagMiddleware("t2\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newChain.Then(testApp).ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("InsertAfter %s does not add handlers correctly: %q", test.name, w.Body.String())
+		}
+	}
+}
+
+func TestInsertAfterRejectsUnknownName(t *testing.T) {
+	chain := New(Named("t1", tagMiddleware("")))
+
+	if _, err := chain.InsertAfter("t2", tagMiddleware("")); err == nil {
+		t.Error("InsertAfter should return an error for an unknown name")
+	}
+}
+
+func TestRemoveByNameRemovesHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), Named("t2", tagMiddleware("t2\n")), tagMiddleware("t3\n"))
+	newChain, err := chain.RemoveByName("t2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("RemoveByName modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt3\napp\n" {
+		t.Error("RemoveByName does not remove handlers correctly")
+	}
+}
+
+func TestRemoveByNameRejectsUnknownName(t *testing.T) {
+	chain := New(Named("t1", tagMiddleware("")))
+
+	if _, err := chain.RemoveByName("t2"); err == nil {
+		t.Error("RemoveByName should return an error for an unknown name")
+	}
+}
+
+func TestReplaceByNameReplacesHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), Named("t2", tagMiddleware("t2\n")), tagMiddleware("t3\n"))
+	newChain, err := chain.ReplaceByName("t2", tagMiddleware("stub\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nt2\nt3\napp\n" {
+		t.Error("ReplaceByName modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "t1\nstub\nt3\napp\n" {
+		t.Error("ReplaceByName does not replace handlers correctly")
+	}
+	if name, ok := Name(newChain.constructors[1]); !ok || name != "t2" {
+		t.Error("ReplaceByName does not keep the name")
+	}
+}
+
+func TestReplaceByNameRejectsUnknownName(t *testing.T) {
+	chain := New(Named("t1", tagMiddleware("")))
+
+	if _, err := chain.ReplaceByName("t2", tagMiddleware("")); err == nil {
+		t.Error("ReplaceByName should return an error for an unknown name")
+	}
+}
+
+func TestDedupRemovesDuplicatesCorrectly(t *testing.T) {
+	chain1 := New(t1Middleware, t2Middleware, Named("n", tagMiddleware("n1\n")))
+	chain2 := New(t3Middleware, t1Middleware, Named("n", tagMiddleware("n2\n")))
+	chain := Compose(chain1, chain2)
+	newChain := chain.Dedup()
+
+	if len(chain.constructors) != 6 {
+		t.Error("chain should have 6 constructors")
+	}
+	if len(newChain.constructors) != 4 {
+		t.Error("newChain should have 4 constructors")
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newChain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nn1\nt3\napp\n" {
+		t.Errorf("Dedup does not remove duplicates correctly: %q", w.Body.String())
+	}
+}
+
+func TestDedupKeepsSeparatelyCreatedClosures(t *testing.T) {
+	th1, th2 := new(throttler), new(throttler)
+
+	tests := map[string]Chain{
+		"SetHeaders":    New(SetHeaders(map[string]string{"A": "a"}), SetHeaders(map[string]string{"B": "b"})),
+		"OnPrefix":      New(OnPrefix("/a", t1Middleware), OnPrefix("/b", t2Middleware)),
+		"Prioritized":   New(Prioritized(1, t1Middleware), Prioritized(1, t2Middleware)),
+		"method values": New(th1.Throttle, th2.Throttle),
+		"tagMiddleware": New(tagMiddleware("t1\n"), tagMiddleware("t2\n")),
+	}
+
+	for name, chain := range tests {
+		if chain.Dedup().Len() != 2 {
+			t.Errorf("Dedup drops a separately created %s constructor", name)
+		}
+	}
+
+	chained := New(SetHeaders(map[string]string{"A": "a"}), SetHeaders(map[string]string{"B": "b"})).Dedup().Then(testApp)
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Header().Get("A") != "a" || w.Header().Get("B") != "b" {
+		t.Errorf("Dedup does not keep both SetHeaders: %v", w.Header())
+	}
+}
+
+func TestForEachIteratesInOrder(t *testing.T) {
+	chain := New(Named("t1", tagMiddleware("")), tagMiddleware(""), Named("t3", tagMiddleware("")))
+
+	var visited []string
+	chain.ForEach(func(index int, c Constructor) {
+		name, _ := Name(c)
+		visited = append(visited, strconv.Itoa(index)+":"+name)
+	})
+
+	if strings.Join(visited, " ") != "0:t1 1: 2:t3" {
+		t.Errorf("ForEach does not iterate in order: %v", visited)
+	}
+}
+
+func TestThenFuncsCallsHandlersInOrder(t *testing.T) {
+	fn := func(tag string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(tag))
+		}
+	}
+
+	chained := New(tagMiddleware("t1\n"), tagMiddleware("t2\n")).ThenFuncs(fn("fn1\n"), fn("fn2\n"))
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nfn1\nfn2\n" {
+		t.Error("ThenFuncs does not call handlers in order")
+	}
+}
+
+func TestThenFuncsTreatsNoneAsDefaultServeMux(t *testing.T) {
+	if New().ThenFuncs() != http.DefaultServeMux {
+		t.Error("ThenFuncs does not treat no handler as DefaultServeMux")
+	}
+}
+
+func TestThenLayersReturnsLayersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+	layers := chain.ThenLayers(testApp)
+
+	if len(layers) != chain.Len()+1 {
+		t.Fatalf("ThenLayers should return %d layers, got %d", chain.Len()+1, len(layers))
+	}
+
+	expected := []string{"t1\nt2\napp\n", "t2\napp\n", "app\n"}
+	for i, layer := range layers {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		layer.ServeHTTP(w, r)
+
+		if w.Body.String() != expected[i] {
+			t.Errorf("ThenLayers does not return layer %d correctly: %q", i, w.Body.String())
+		}
+	}
+}
+
+func TestThenLayersKeepsHandlerOfRecoveringChain(t *testing.T) {
+	panicApp := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("app")
+	})
+	layers := New().WithDefaultRecovery().ThenLayers(panicApp)
+
+	if len(layers) != 2 {
+		t.Fatalf("ThenLayers should return 2 layers, got %d", len(layers))
+	}
+	if !funcsEqual(layers[1], panicApp) {
+		t.Error("ThenLayers does not return the given handler as the last layer")
+	}
+
+	w := httptest.NewRecorder()
+	layers[0].ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("ThenLayers does not return the recovering handler first: %d", w.Code)
+	}
+}
+
+func TestCountCountsMatchesCorrectly(t *testing.T) {
+	shared := Constructor(t2Middleware)
+	chain := New(Named("auth", tagMiddleware("")), shared, t1Middleware, shared)
+
+	byName := chain.Count(func(c Constructor) bool {
+		name, _ := Name(c)
+		return name == "auth"
+	})
+	if byName != 1 {
+		t.Errorf("Count should find 1 constructor by name, got %d", byName)
+	}
+
+	byIdentity := chain.Count(func(c Constructor) bool {
+		return sameCode(c, shared)
+	})
+	if byIdentity != 2 {
+		t.Errorf("Count should find 2 constructors by identity, got %d", byIdentity)
+	}
+
+	if New().Count(func(Constructor) bool { return true }) != 0 {
+		t.Error("Count should find nothing in an empty chain")
+	}
+}
+
+func TestIndexOfFindsConstructorsCorrectly(t *testing.T) {
+	th := &throttler{}
+	chain := New(t1Middleware, th.Throttle, t2Middleware, tagMiddleware("t3\n"))
+
+	if chain.IndexOf(t2Middleware) != 2 {
+		t.Error("IndexOf does not find a function correctly")
+	}
+	if chain.IndexOf(th.Throttle) != 1 {
+		t.Error("IndexOf does not find a method value correctly")
+	}
+	if chain.IndexOf(tagMiddleware("")) != 3 {
+		t.Error("IndexOf does not find a closure correctly")
+	}
+	if chain.IndexOf(t3Middleware) != -1 {
+		t.Error("IndexOf should not find a constructor absent from the chain")
+	}
+}
+
+func TestIndexOfComparesWrappedConstructors(t *testing.T) {
+	chain := New(Prioritized(1, t1Middleware), Prioritized(1, t2Middleware))
+
+	if chain.IndexOf(Prioritized(1, t2Middleware)) != 1 {
+		t.Error("IndexOf does not compare the constructors wrapped by Prioritized")
+	}
+}
+
+func TestConstructorNestsChainCorrectly(t *testing.T) {
+	group := New(tagMiddleware("t2\n"), tagMiddleware("t3\n"))
+	chained := New(tagMiddleware("t1\n"), group.Constructor(), tagMiddleware("t4\n")).Then(testApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\nt3\nt4\napp\n" {
+		t.Error("Constructor does not nest the chain correctly")
+	}
+}
+
+func TestPartialMatchesThen(t *testing.T) {
+	builds := 0
+	chain := New(countingMiddleware(&builds), tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+	attach := chain.Partial()
+
+	other := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("other\n"))
+	})
+
+	for _, app := range []http.Handler{testApp, other, testApp} {
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		partial := httptest.NewRecorder()
+		attach(app).ServeHTTP(partial, r)
+		then := httptest.NewRecorder()
+		chain.Then(app).ServeHTTP(then, r)
+
+		if partial.Body.String() != then.Body.String() {
+			t.Errorf("Partial does not match Then: %q, %q", partial.Body.String(), then.Body.String())
+		}
+	}
+
+	if builds != 4 {
+		t.Errorf("Partial should build the chain once, the chain was built %d times", builds)
+	}
+}
+
+func TestPartialNests(t *testing.T) {
+	inner := New(tagMiddleware("t1\n")).Partial()
+	outer := New(inner, tagMiddleware("t2\n")).Partial()
+
+	w := httptest.NewRecorder()
+	outer(testApp).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Body.String() != "t1\nt2\napp\n" {
+		t.Errorf("Partial does not nest correctly: %q", w.Body.String())
+	}
+}
+
+func TestPartialPanicsWithoutTerminalHandler(t *testing.T) {
+	freshContext := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r.WithContext(context.Background()))
+		})
+	}
+	attach := New(freshContext).Partial()
+
+	defer func() {
+		if v := recover(); v != "alice: Partial terminal handler missing from the request context" {
+			t.Errorf("Partial panicked with %v", v)
+		}
+	}()
+	attach(testApp).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestValidateAllReportsEveryPanickingConstructor(t *testing.T) {
+	broken := func(h http.Handler) http.Handler {
+		panic("broken")
+	}
+	chain := New(broken, tagMiddleware(""), broken)
+
+	err := chain.ValidateAll()
+	if err == nil {
+		t.Fatal("ValidateAll should return an error")
+	}
+	if err.Error() != "alice: constructor #0: panic: broken\nalice: constructor #2: panic: broken" {
+		t.Errorf("ValidateAll does not report every failing constructor: %v", err)
+	}
+
+	if err := New(tagMiddleware("")).ValidateAll(); err != nil {
+		t.Errorf("ValidateAll should not return an error: %v", err)
+	}
+}
+
+func TestIdentityDoesNothing(t *testing.T) {
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	with := httptest.NewRecorder()
+	New(tagMiddleware("t1\n"), Identity, tagMiddleware("t2\n")).Then(testApp).ServeHTTP(with, r)
+	without := httptest.NewRecorder()
+	New(tagMiddleware("t1\n"), tagMiddleware("t2\n")).Then(testApp).ServeHTTP(without, r)
+
+	if with.Body.String() != without.Body.String() {
+		t.Error("Identity should not change the chain output")
+	}
+	if !funcsEqual(New(Identity).Then(testApp), testApp) {
+		t.Error("Identity should return the next handler")
+	}
+}
+
+func TestThenOrElseBuildsWorkingChain(t *testing.T) {
+	fallback := http.NotFoundHandler()
+	chained := New(tagMiddleware("t1\n")).ThenOrElse(testApp, fallback)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\napp\n" {
+		t.Error("ThenOrElse does not build a working chain")
+	}
+}
+
+func TestThenOrElseFallsBackOnPanic(t *testing.T) {
+	fallback := http.NotFoundHandler()
+	chained := New(tagMiddleware("t1\n"), func(h http.Handler) http.Handler {
+		panic("broken")
+	}).ThenOrElse(testApp, fallback)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("ThenOrElse does not fall back when a constructor panics: %d", w.Code)
+	}
+}
+
+func TestEqualComparesChainsCorrectly(t *testing.T) {
+	t1, t2 := Constructor(t1Middleware), Constructor(t2Middleware)
+	slice := []Constructor{t1, t2}
+
+	tests := []struct {
+		c1, c2   Chain
+		expected bool
+	}{
+		{New(slice...), New(slice...), true},
+		{New(), New(), true},
+		{New(Named("t", t1)), New(Named("t", t2)), true},
+		{New(t1, t2), New(t2, t1), false},
+		{New(t1, t2), New(t1, t2, t2), false},
+		{New(t1), New(tagMiddleware("t1\n")), false},
+		{New(tagMiddleware("t1\n")), New(tagMiddleware("t2\n")), false},
+		{New(Timeout(time.Second)), New(Timeout(time.Hour)), false},
+		{New(Prioritized(1, t1)), New(Prioritized(1, t2)), false},
+		{New(Prioritized(1, t1)), New(Prioritized(1, t1)), true},
+		{New(Named("t1", t1)), New(Named("t2", t1)), false},
+		{New(t1, t2), New(t1, t2).Reversed(), false},
+		{New(t1), New(t1).WithDefaultRecovery(), false},
+		{New(t1).WithDefaultRecovery(), New(t1).WithDefaultRecovery(), true},
+		{New(t1), New(t1).WithDefaultHandler(testApp), false},
+		{New(t1).WithDefaultHandler(testApp), New(t1).WithDefaultHandler(testApp), true},
+		{New(t1).WithDefaultHandler(http.NewServeMux()), New(t1).WithDefaultHandler(http.NewServeMux()), false},
+		{New(t1).WithDefaultHandler(tagMiddleware("a")(testApp)), New(t1).WithDefaultHandler(tagMiddleware("a")(testApp)), false},
+		{New(t1).WithDefaultHandler(wrappedHandler{testApp}), New(t1).WithDefaultHandler(wrappedHandler{testApp}), false},
+	}
+
+	for i, test := range tests {
+		if test.c1.Equal(test.c2) != test.expected {
+			t.Errorf("Equal of %s and %s (case %d) should be %v", test.c1, test.c2, i, test.expected)
+		}
+	}
+}
+
+func TestSpliceReplacesHandlersCorrectly(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"), tagMiddleware("t3\n"), tagMiddleware("t4\n"))
+
+	tests := []struct {
+		start, end  int
+		replacement []Constructor
+		expected    string
+	}{
+		{1, 3, []Constructor{tagMiddleware("r1\n")}, "t1\nr1\nt4\napp\n"},
+		{1, 2, []Constructor{tagMiddleware("r1\n"), tagMiddleware("r2\n")}, "t1\nr1\nr2\nt3\nt4\napp\n"},
+		{2, 2, []Constructor{tagMiddleware("r1\n")}, "t1\nt2\nr1\nt3\nt4\napp\n"},
+		{0, 4, nil, "app\n"},
+	}
+
+	for _, test := range tests {
+		newChain, err := chain.Splice(test.start, test.end, test.replacement...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chain.constructors) != 4 {
+			t.Error("chain should have 4 constructors")
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newChain.Then(testApp).ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("Splice [%d, %d) does not replace handlers correctly: %q", test.start, test.end, w.Body.String())
+		}
+	}
+}
+
+func TestSpliceRejectsInvalidBounds(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+
+	for _, bounds := range [][2]int{{-1, 1}, {2, 1}, {0, 3}} {
+		if _, err := chain.Splice(bounds[0], bounds[1], tagMiddleware("")); err == nil {
+			t.Errorf("Splice %v should return an error", bounds)
+		}
+	}
+}
+
+func TestSpliceRespectsImmutability(t *testing.T) {
+	chain := New(tagMiddleware(""), tagMiddleware(""))
+	newChain, err := chain.Splice(1, 2, tagMiddleware(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("Splice does not respect immutability")
+	}
+}
+
+func TestFirstAndLastReturnConstructorsCorrectly(t *testing.T) {
+	t1, t2, t3 := Constructor(t1Middleware), Constructor(t2Middleware), Constructor(t3Middleware)
+	chain := New(t1, t2, t3)
+
+	if first, ok := chain.First(); !ok || !sameCode(first, t1) {
+		t.Error("First does not return the first constructor")
+	}
+	if last, ok := chain.Last(); !ok || !sameCode(last, t3) {
+		t.Error("Last does not return the last constructor")
+	}
+
+	if _, ok := New().First(); ok {
+		t.Error("First should not find a constructor in an empty chain")
+	}
+	if _, ok := New().Last(); ok {
+		t.Error("Last should not find a constructor in an empty chain")
+	}
+}
+
+func TestThenIsSafeForConcurrentUse(t *testing.T) {
+	chain := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			chain.Then(testApp).ServeHTTP(w, r)
+			chain.Compile(http.NewServeMux())
+
+			if w.Body.String() != "t1\nt2\napp\n" {
+				t.Error("Then does not order handlers correctly when used concurrently")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBuiltHandlerIsSafeForConcurrentUse(t *testing.T) {
+	chained := New(tagMiddleware("t1\n"), tagMiddleware("t2\n"), tagMiddleware("t3\n")).Then(testApp)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				w := httptest.NewRecorder()
+				r, err := http.NewRequest("GET", "/", nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				chained.ServeHTTP(w, r)
+
+				if w.Body.String() != "t1\nt2\nt3\napp\n" {
+					t.Error("Built handler does not order handlers correctly when used concurrently")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestThenTimedReportsBuildTime(t *testing.T) {
+	slow := func(h http.Handler) http.Handler {
+		time.Sleep(10 * time.Millisecond)
+		return h
+	}
+
+	chained, d := New(slow, tagMiddleware("t1\n")).ThenTimed(testApp)
+
+	if d < 10*time.Millisecond {
+		t.Errorf("ThenTimed should report at least 10ms, got %s", d)
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\napp\n" {
+		t.Error("ThenTimed does not order handlers correctly")
+	}
+}
@@ -0,0 +1,209 @@
+package alice
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// When returns a constructor for middleware
+// that applies c only to requests for which pred returns true.
+// Other requests are passed straight to the next handler.
+//
+// c is called once, when the chain is built;
+// pred is called on every request.
+//
+//	alice.New(m1, alice.When(isAPI, m2)).Then(h)
+//	// API requests go m1 -> m2 -> h
+//	// other requests go m1 -> h
+func When(pred func(*http.Request) bool, c Constructor) Constructor {
+	return Branch(pred, c, Identity)
+}
+
+// Unless is the inverse of When:
+// it applies c only to requests for which pred returns false.
+//
+//	alice.New(alice.Unless(isHealthCheck, auth)).Then(h)
+func Unless(pred func(*http.Request) bool, c Constructor) Constructor {
+	return When(func(r *http.Request) bool {
+		return !pred(r)
+	}, c)
+}
+
+// Branch returns a constructor for middleware
+// that passes requests for which pred returns true to ifTrue,
+// and the other ones to ifFalse.
+// Both continue to the next handler.
+//
+// Both ifTrue and ifFalse are called once, when the chain is built;
+// pred is called on every request.
+//
+//	alice.New(alice.Branch(isAuthenticated, userLimit, anonLimit)).Then(h)
+func Branch(pred func(*http.Request) bool, ifTrue, ifFalse Constructor) Constructor {
+	return func(h http.Handler) http.Handler {
+		onTrue := ifTrue(h)
+		onFalse := ifFalse(h)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if pred(r) {
+				onTrue.ServeHTTP(w, r)
+			} else {
+				onFalse.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// OnMethods returns a constructor for middleware
+// that applies c only to requests using one of the given methods,
+// compared case-insensitively.
+// Other requests are passed straight to the next handler,
+// as are all requests if no method is given.
+//
+//	alice.New(alice.OnMethods([]string{"POST", "PUT"}, csrf)).Then(h)
+func OnMethods(methods []string, c Constructor) Constructor {
+	methods = append(([]string)(nil), methods...)
+
+	return When(func(r *http.Request) bool {
+		for _, method := range methods {
+			if strings.EqualFold(r.Method, method) {
+				return true
+			}
+		}
+		return false
+	}, c)
+}
+
+// OnPrefix returns a constructor for middleware
+// that applies c only to requests whose URL path starts with prefix.
+// Other requests are passed straight to the next handler.
+//
+// The path is left untouched:
+// combine OnPrefix with http.StripPrefix to remove the prefix.
+//
+//	alice.New(alice.OnPrefix("/admin/", auth)).Then(h)
+func OnPrefix(prefix string, c Constructor) Constructor {
+	return When(func(r *http.Request) bool {
+		return strings.HasPrefix(r.URL.Path, prefix)
+	}, c)
+}
+
+// OnHost returns a constructor for middleware
+// that applies c only to requests for the given host,
+// compared case-insensitively and regardless of the port.
+// Other requests are passed straight to the next handler.
+//
+//	alice.New(alice.OnHost("api.example.com", cors)).Then(h)
+func OnHost(host string, c Constructor) Constructor {
+	host = hostname(host)
+
+	return When(func(r *http.Request) bool {
+		return strings.EqualFold(hostname(r.Host), host)
+	}, c)
+}
+
+// hostname returns host without its port, if any.
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+// OnHeader returns a constructor for middleware
+// that applies c only to requests carrying
+// the header name with the given value.
+// Other requests are passed straight to the next handler.
+//
+//	alice.New(alice.OnHeader("X-Feature", "beta", betaMiddleware)).Then(h)
+func OnHeader(name, value string, c Constructor) Constructor {
+	return When(func(r *http.Request) bool {
+		for _, v := range r.Header[http.CanonicalHeaderKey(name)] {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}, c)
+}
+
+// OnHeaderPresent returns a constructor for middleware
+// that applies c only to requests carrying the header name,
+// whatever its value.
+// Other requests are passed straight to the next handler.
+func OnHeaderPresent(name string, c Constructor) Constructor {
+	return When(func(r *http.Request) bool {
+		_, ok := r.Header[http.CanonicalHeaderKey(name)]
+		return ok
+	}, c)
+}
+
+// Flag returns a constructor for middleware
+// that applies c only while enabled returns true,
+// letting a feature flag system switch middleware on and off
+// without rebuilding the chain.
+// enabled is called on every request.
+//
+//	alice.New(alice.Flag(flags.Bool("new-cache"), newCache)).Then(h)
+func Flag(enabled func() bool, c Constructor) Constructor {
+	return When(func(*http.Request) bool {
+		return enabled()
+	}, c)
+}
+
+// Gate returns a constructor for middleware
+// that lets requests for which decide returns true through,
+// and serves the other ones with deny,
+// without calling the next handler.
+//
+// Gate treats a nil deny as a handler responding 403 Forbidden.
+//
+//	alice.New(alice.Gate(notInMaintenance, maintenancePage)).Then(h)
+func Gate(decide func(*http.Request) bool, deny http.Handler) Constructor {
+	if deny == nil {
+		deny = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		})
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if decide(r) {
+				h.ServeHTTP(w, r)
+			} else {
+				deny.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// Sample returns a constructor for middleware
+// that applies c to a random fraction of the requests,
+// for instance to roll out new middleware gradually.
+// Other requests are passed straight to the next handler.
+//
+// fraction is clamped to [0, 1]:
+// 0 or less never applies c, 1 or more always does.
+// If a seed is given, requests are picked
+// with a random number generator seeded with it,
+// making the selection reproducible;
+// otherwise, the math/rand default source is used.
+//
+//	alice.New(alice.Sample(0.05, newCache)).Then(h)
+func Sample(fraction float64, c Constructor, seed ...int64) Constructor {
+	random := rand.Float64
+	if len(seed) > 0 {
+		var mu sync.Mutex
+		rng := rand.New(rand.NewSource(seed[0]))
+		random = func() float64 {
+			mu.Lock()
+			defer mu.Unlock()
+			return rng.Float64()
+		}
+	}
+
+	return When(func(*http.Request) bool {
+		return random() < fraction
+	}, c)
+}
@@ -0,0 +1,435 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func isAdmin(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/admin")
+}
+
+func TestWhenAppliesMiddlewareConditionally(t *testing.T) {
+	chained := New(tagMiddleware("t1\n"), When(isAdmin, tagMiddleware("t2\n"))).Then(testApp)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/admin/users", "t1\nt2\napp\n"},
+		{"/users", "t1\napp\n"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("When does not apply middleware correctly for %s: %q", test.path, w.Body.String())
+		}
+	}
+}
+
+func TestWhenBuildsMiddlewareOnce(t *testing.T) {
+	builds := 0
+	chained := New(When(isAdmin, countingMiddleware(&builds))).Then(testApp)
+
+	for _, path := range []string{"/admin", "/", "/admin"} {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chained.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if builds != 1 {
+		t.Errorf("When should build the middleware once, built %d times", builds)
+	}
+}
+
+func TestUnlessAppliesMiddlewareConditionally(t *testing.T) {
+	chained := New(Unless(isAdmin, tagMiddleware("t1\n"))).Then(testApp)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/admin/users", "app\n"},
+		{"/users", "t1\napp\n"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("Unless does not apply middleware correctly for %s: %q", test.path, w.Body.String())
+		}
+	}
+}
+
+func TestUnlessBuildsMiddlewareOnce(t *testing.T) {
+	builds := 0
+	chained := New(Unless(isAdmin, countingMiddleware(&builds))).Then(testApp)
+
+	for _, path := range []string{"/admin", "/", "/admin"} {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chained.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if builds != 1 {
+		t.Errorf("Unless should build the middleware once, built %d times", builds)
+	}
+}
+
+func TestBranchSelectsMiddlewareCorrectly(t *testing.T) {
+	chained := New(Branch(isAdmin, tagMiddleware("t1\n"), tagMiddleware("t2\n"))).Then(testApp)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/admin/users", "t1\napp\n"},
+		{"/users", "t2\napp\n"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("Branch does not select middleware correctly for %s: %q", test.path, w.Body.String())
+		}
+	}
+}
+
+func TestBranchBuildsBothMiddlewareOnce(t *testing.T) {
+	trueBuilds, falseBuilds := 0, 0
+	chained := New(Branch(isAdmin, countingMiddleware(&trueBuilds), countingMiddleware(&falseBuilds))).Then(testApp)
+
+	if trueBuilds != 1 || falseBuilds != 1 {
+		t.Error("Branch should build both middleware when the chain is built")
+	}
+
+	for _, path := range []string{"/admin", "/", "/admin"} {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chained.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if trueBuilds != 1 || falseBuilds != 1 {
+		t.Error("Branch should not build middleware when serving requests")
+	}
+}
+
+func TestGateAllowsAndDeniesCorrectly(t *testing.T) {
+	deny := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("denied\n"))
+	})
+	chained := New(tagMiddleware("t1\n"), Gate(isAdmin, deny), tagMiddleware("t2\n")).Then(testApp)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/admin/users", "t1\nt2\napp\n"},
+		{"/users", "t1\ndenied\n"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("Gate does not decide correctly for %s: %q", test.path, w.Body.String())
+		}
+	}
+}
+
+func TestGateTreatsNilDenyAsForbidden(t *testing.T) {
+	chained := New(Gate(isAdmin, nil)).Then(testApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/users", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Gate should respond 403 by default, got %d", w.Code)
+	}
+}
+
+// sampled serves n requests with chained
+// and returns how many went through the "sampled" tag.
+func sampled(t *testing.T, chained http.Handler, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() == "sampled\napp\n" {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestSampleAppliesMiddlewareToFraction(t *testing.T) {
+	chained := New(Sample(0.3, tagMiddleware("sampled\n"), 42)).Then(testApp)
+
+	count := sampled(t, chained, 1000)
+	if count < 250 || count > 350 {
+		t.Errorf("Sample should apply the middleware to about 300 requests, applied it to %d", count)
+	}
+
+	again := New(Sample(0.3, tagMiddleware("sampled\n"), 42)).Then(testApp)
+	if sampled(t, again, 1000) != count {
+		t.Error("Sample should be reproducible with a seed")
+	}
+}
+
+func TestSampleDegeneratesAtBounds(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		expected int
+	}{
+		{-1, 0},
+		{0, 0},
+		{1, 100},
+		{2, 100},
+	}
+
+	for _, test := range tests {
+		chained := New(Sample(test.fraction, tagMiddleware("sampled\n"), 1)).Then(testApp)
+		if count := sampled(t, chained, 100); count != test.expected {
+			t.Errorf("Sample with %v should apply the middleware %d times, applied it %d times", test.fraction, test.expected, count)
+		}
+	}
+}
+
+func TestOnMethodsAppliesMiddlewareConditionally(t *testing.T) {
+	tests := []struct {
+		methods  []string
+		method   string
+		expected string
+	}{
+		{[]string{"POST"}, "POST", "t1\napp\n"},
+		{[]string{"post"}, "POST", "t1\napp\n"},
+		{[]string{"POST"}, "GET", "app\n"},
+		{nil, "GET", "app\n"},
+	}
+
+	for _, test := range tests {
+		chained := New(OnMethods(test.methods, tagMiddleware("t1\n"))).Then(testApp)
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest(test.method, "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("OnMethods %v does not apply middleware correctly for %s: %q", test.methods, test.method, w.Body.String())
+		}
+	}
+}
+
+// newRequest returns a request for the given method and target,
+// failing the test if it cannot be created.
+func newRequest(t *testing.T, method, target string) *http.Request {
+	r, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestOnPrefixAppliesMiddlewareConditionally(t *testing.T) {
+	var paths []string
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		testApp(w, r)
+	})
+	chained := New(OnPrefix("/admin/", tagMiddleware("t1\n"))).Then(app)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/admin/users", "t1\napp\n"},
+		{"/admin", "app\n"},
+		{"/users/admin/", "app\n"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, newRequest(t, "GET", test.path))
+
+		if w.Body.String() != test.expected {
+			t.Errorf("OnPrefix does not apply middleware correctly for %s: %q", test.path, w.Body.String())
+		}
+	}
+
+	if paths[0] != "/admin/users" {
+		t.Error("OnPrefix should not strip the prefix")
+	}
+}
+
+func TestOnHostAppliesMiddlewareConditionally(t *testing.T) {
+	chained := New(OnHost("example.com", tagMiddleware("t1\n"))).Then(testApp)
+
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"example.com", "t1\napp\n"},
+		{"Example.COM", "t1\napp\n"},
+		{"example.com:8080", "t1\napp\n"},
+		{"example.org", "app\n"},
+		{"api.example.com", "app\n"},
+	}
+
+	for _, test := range tests {
+		r := newRequest(t, "GET", "/")
+		r.Host = test.host
+
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("OnHost does not apply middleware correctly for %s: %q", test.host, w.Body.String())
+		}
+	}
+}
+
+func TestOnHostHandlesIPv6(t *testing.T) {
+	chained := New(OnHost("[::1]:80", tagMiddleware("t1\n"))).Then(testApp)
+
+	for _, host := range []string{"[::1]", "[::1]:8080"} {
+		r := newRequest(t, "GET", "/")
+		r.Host = host
+
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != "t1\napp\n" {
+			t.Errorf("OnHost does not apply middleware correctly for %s: %q", host, w.Body.String())
+		}
+	}
+}
+
+func TestOnHeaderAppliesMiddlewareConditionally(t *testing.T) {
+	chained := New(OnHeader("x-feature", "beta", tagMiddleware("t1\n"))).Then(testApp)
+
+	tests := []struct {
+		value    []string
+		expected string
+	}{
+		{[]string{"beta"}, "t1\napp\n"},
+		{[]string{"alpha", "beta"}, "t1\napp\n"},
+		{[]string{"alpha"}, "app\n"},
+		{nil, "app\n"},
+	}
+
+	for _, test := range tests {
+		r := newRequest(t, "GET", "/")
+		for _, v := range test.value {
+			r.Header.Add("X-Feature", v)
+		}
+
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("OnHeader does not apply middleware correctly for %v: %q", test.value, w.Body.String())
+		}
+	}
+}
+
+func TestOnHeaderPresentAppliesMiddlewareConditionally(t *testing.T) {
+	chained := New(OnHeaderPresent("X-Feature", tagMiddleware("t1\n"))).Then(testApp)
+
+	tests := []struct {
+		value    []string
+		expected string
+	}{
+		{[]string{"beta"}, "t1\napp\n"},
+		{[]string{""}, "t1\napp\n"},
+		{nil, "app\n"},
+	}
+
+	for _, test := range tests {
+		r := newRequest(t, "GET", "/")
+		for _, v := range test.value {
+			r.Header.Add("X-Feature", v)
+		}
+
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("OnHeaderPresent does not apply middleware correctly for %v: %q", test.value, w.Body.String())
+		}
+	}
+}
+
+func TestFlagTogglesMiddleware(t *testing.T) {
+	enabled := false
+	flag := func() bool {
+		return enabled
+	}
+	chained := New(Flag(flag, tagMiddleware("t1\n"))).Then(testApp)
+
+	for _, state := range []bool{false, true, false} {
+		enabled = state
+
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, newRequest(t, "GET", "/"))
+
+		expected := "app\n"
+		if state {
+			expected = "t1\napp\n"
+		}
+		if w.Body.String() != expected {
+			t.Errorf("Flag does not toggle middleware correctly when %v: %q", state, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package alice
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WithContextValue returns a constructor for middleware
+// passing to the next handler a request
+// whose context carries val under key,
+// as context.WithValue does.
+//
+//	alice.New(alice.WithContextValue(dbKey{}, db)).Then(h)
+func WithContextValue(key, val interface{}) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), key, val)))
+		})
+	}
+}
+
+// DeadlineFromHeader returns a constructor for middleware
+// letting clients bound their own requests:
+// a duration such as "2s" found in the header of a request,
+// as parsed by time.ParseDuration,
+// becomes the deadline of the request context.
+// Missing, malformed and non-positive durations are ignored.
+// An empty header means X-Request-Timeout.
+//
+//	alice.New(alice.DeadlineFromHeader("")).Then(h)
+func DeadlineFromHeader(header string) Constructor {
+	if header == "" {
+		header = "X-Request-Timeout"
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d, err := time.ParseDuration(r.Header.Get(header))
+			if err != nil || d <= 0 {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testContextKey struct{}
+
+func TestWithContextValueInjectsValue(t *testing.T) {
+	var got interface{}
+	chained := New(WithContextValue(testContextKey{}, "value")).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Context().Value(testContextKey{})
+	})
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got != "value" {
+		t.Errorf("WithContextValue does not inject the value: %v", got)
+	}
+	if r.Context().Value(testContextKey{}) != nil {
+		t.Error("WithContextValue should not modify the original request")
+	}
+}
+
+func TestDeadlineFromHeader(t *testing.T) {
+	var deadline time.Time
+	var ok bool
+	chained := New(DeadlineFromHeader("")).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok = r.Context().Deadline()
+	})
+
+	tests := []struct {
+		value    string
+		deadline bool
+	}{
+		{"2s", true},
+		{"", false},
+		{"soon", false},
+		{"-1s", false},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.value != "" {
+			r.Header.Set("X-Request-Timeout", test.value)
+		}
+		start := time.Now()
+		chained.ServeHTTP(httptest.NewRecorder(), r)
+
+		if ok != test.deadline {
+			t.Errorf("%q: request context has a deadline: %v", test.value, ok)
+		}
+		if ok && (deadline.Before(start.Add(time.Second)) || deadline.After(time.Now().Add(2*time.Second))) {
+			t.Errorf("%q: request context deadline is %v away", test.value, deadline.Sub(start))
+		}
+	}
+}
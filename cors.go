@@ -0,0 +1,91 @@
+package alice
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the middleware returned by CORS.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to make requests.
+	// "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedMethods lists the methods allowed in preflight requests.
+	// Empty means GET, HEAD and POST.
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed in preflight requests.
+	AllowedHeaders []string
+	// AllowCredentials allows requests with credentials.
+	AllowCredentials bool
+	// MaxAge is how long the result of a preflight request can be cached.
+	// Zero leaves it to the client.
+	MaxAge time.Duration
+}
+
+// CORS returns a constructor for middleware
+// handling cross-origin requests as configured by opts.
+//
+// Preflight requests, OPTIONS requests with an Origin
+// and an Access-Control-Request-Method header,
+// are answered with 204 No Content and never reach the next handler.
+// Other requests from an allowed origin get the
+// Access-Control-Allow-* headers and are passed on.
+//
+//	alice.New(alice.CORS(alice.CORSOptions{
+//		AllowedOrigins: []string{"https://example.com"},
+//		AllowedMethods: []string{"GET", "PUT"},
+//	})).Then(h)
+func CORS(opts CORSOptions) Constructor {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+
+	origins := make(map[string]bool, len(opts.AllowedOrigins))
+	for _, o := range opts.AllowedOrigins {
+		origins[o] = true
+	}
+	allowed := func(origin string) bool {
+		return origin != "" && (origins["*"] || origins[origin])
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && origin != "" &&
+				r.Header.Get("Access-Control-Request-Method") != ""
+
+			header := w.Header()
+			header.Add("Vary", "Origin")
+			if allowed(origin) {
+				if origins["*"] && !opts.AllowCredentials {
+					header.Set("Access-Control-Allow-Origin", "*")
+				} else {
+					header.Set("Access-Control-Allow-Origin", origin)
+				}
+				if opts.AllowCredentials {
+					header.Set("Access-Control-Allow-Credentials", "true")
+				}
+				if preflight {
+					header.Set("Access-Control-Allow-Methods", allowMethods)
+					if allowHeaders != "" {
+						header.Set("Access-Control-Allow-Headers", allowHeaders)
+					}
+					if opts.MaxAge > 0 {
+						header.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge/time.Second)))
+					}
+				}
+			}
+
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testCORS = CORSOptions{
+	AllowedOrigins:   []string{"https://example.com"},
+	AllowedMethods:   []string{"GET", "PUT"},
+	AllowedHeaders:   []string{"Authorization"},
+	AllowCredentials: true,
+	MaxAge:           time.Hour,
+}
+
+func TestCORSPreflight(t *testing.T) {
+	called := false
+	chained := New(CORS(testCORS)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest("OPTIONS", "/", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, req)
+
+	if called {
+		t.Error("CORS passed a preflight request on")
+	}
+
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":      "https://example.com",
+		"Access-Control-Allow-Methods":     "GET, PUT",
+		"Access-Control-Allow-Headers":     "Authorization",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "3600",
+	}
+	if w.Code != http.StatusNoContent {
+		t.Errorf("preflight status is %d", w.Code)
+	}
+	for k, v := range expected {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s is %q, expected %q", k, got, v)
+		}
+	}
+}
+
+func TestCORSRequest(t *testing.T) {
+	called := false
+	chained := New(CORS(testCORS)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://example.com")
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, req)
+
+	if !called {
+		t.Error("CORS did not pass the request on")
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Errorf("Access-Control-Allow-Origin is %q", got)
+	}
+	if w.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Error("Access-Control-Allow-Methods set outside of preflight")
+	}
+}
+
+func TestCORSOtherOrigin(t *testing.T) {
+	chained := New(CORS(testCORS)).Then(testApp)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin is %q for a disallowed origin", got)
+	}
+}
+
+func TestCORSLetsOtherOptionsRequestsThrough(t *testing.T) {
+	called := false
+	chained := New(CORS(testCORS)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest("OPTIONS", "/", nil)
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	chained.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !called {
+		t.Error("CORS swallowed an OPTIONS request without Origin")
+	}
+}
@@ -0,0 +1,157 @@
+package alice
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag returns a constructor for middleware
+// buffering successful responses to GET requests
+// to give them a strong ETag computed over their body,
+// and responding 304 Not Modified instead
+// when the request If-None-Match header matches it.
+// An ETag set by the next handler is kept.
+//
+// Responses larger than 1MiB are streamed to the client without an ETag
+// once they go over that size.
+// Responses to HEAD requests, having no body to compute the ETag over,
+// are left untouched.
+//
+//	alice.New(alice.ETag()).Then(h)
+func ETag() Constructor {
+	return ETagLimit(1 << 20)
+}
+
+// ETagLimit works like ETag,
+// but streams responses larger than limit bytes instead of 1MiB.
+//
+//	alice.New(alice.ETagLimit(64 << 10)).Then(h)
+func ETagLimit(limit int) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			ew := &etagWriter{ResponseWriter: w, limit: limit}
+			h.ServeHTTP(ew, r)
+			ew.finish(r)
+		})
+	}
+}
+
+// etagWriter buffers a response for ETag,
+// until it turns out not to need one.
+type etagWriter struct {
+	http.ResponseWriter
+	limit     int
+	code      int
+	buf       bytes.Buffer
+	streaming bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.code != 0 {
+		return
+	}
+	w.code = code
+	if code != http.StatusOK {
+		w.stream()
+	}
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+
+	if w.buf.Len()+len(b) > w.limit {
+		if err := w.stream(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// Flush streams the response,
+// since it can no longer be buffered.
+func (w *etagWriter) Flush() {
+	if w.code == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.stream()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// stream writes out the status and the buffered body,
+// then lets the rest of the response through.
+func (w *etagWriter) stream() error {
+	if w.streaming {
+		return nil
+	}
+	w.streaming = true
+
+	w.ResponseWriter.WriteHeader(w.code)
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// finish writes out the buffered response, if any,
+// or responds 304 if the client has it already.
+func (w *etagWriter) finish(r *http.Request) {
+	if w.streaming {
+		return
+	}
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	header := w.Header()
+	tag := header.Get("ETag")
+	if tag == "" {
+		sum := sha256.Sum256(w.buf.Bytes())
+		tag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		header.Set("ETag", tag)
+	}
+
+	if etagMatch(r.Header.Get("If-None-Match"), tag) {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	w.ResponseWriter.WriteHeader(w.code)
+	w.ResponseWriter.Write(w.buf.Bytes())
+}
+
+// etagMatch reports whether the If-None-Match header value ifNoneMatch
+// matches tag, using the weak comparison.
+func etagMatch(ifNoneMatch, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, t := range strings.Split(ifNoneMatch, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETagConditionalRequest(t *testing.T) {
+	chained := New(ETag()).Then(testApp)
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	tag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || w.Body.String() != "app\n" || tag == "" {
+		t.Fatalf("first request got %d, %q with ETag %q", w.Code, w.Body.String(), tag)
+	}
+	if w.Header().Get("Content-Length") != "4" {
+		t.Errorf("Content-Length is %q", w.Header().Get("Content-Length"))
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", `"other", `+tag)
+	w = httptest.NewRecorder()
+	chained.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("conditional request got %d, %q", w.Code, w.Body.String())
+	}
+}
+
+func TestETagStreamsLargeResponses(t *testing.T) {
+	chained := New(ETagLimit(8)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello "))
+		w.Write([]byte("world"))
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Body.String() != "hello world" || w.Header().Get("ETag") != "" {
+		t.Errorf("large response got %q with ETag %q", w.Body.String(), w.Header().Get("ETag"))
+	}
+}
+
+func TestETagSkipsErrors(t *testing.T) {
+	chained := New(ETag()).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "missing", http.StatusNotFound)
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusNotFound || !strings.HasPrefix(w.Body.String(), "missing") || w.Header().Get("ETag") != "" {
+		t.Errorf("error response got %d, %q with ETag %q", w.Code, w.Body.String(), w.Header().Get("ETag"))
+	}
+}
+
+func TestETagLeavesHEADUntouched(t *testing.T) {
+	chained := New(ETag()).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "5")
+		if r.Method != "HEAD" {
+			w.Write([]byte("hello"))
+		}
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("HEAD", "/", nil))
+
+	if w.Header().Get("Content-Length") != "5" || w.Header().Get("ETag") != "" {
+		t.Errorf("HEAD response got Content-Length %q, ETag %q", w.Header().Get("Content-Length"), w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,38 @@
+package alice
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NewFromFactories creates a new chain
+// from the constructors returned by the given factories, in order.
+//
+// Every factory is called, even if some of them fail,
+// so that all configuration problems are reported at once:
+// the returned error joins the errors of the failing factories,
+// each wrapped along with the index of its factory.
+//
+//	chain, err := alice.NewFromFactories(
+//		func() (alice.Constructor, error) { return newLogger(cfg.Log) },
+//		func() (alice.Constructor, error) { return newAuth(cfg.Auth) },
+//	)
+func NewFromFactories(factories ...func() (Constructor, error)) (Chain, error) {
+	constructors := make([]Constructor, 0, len(factories))
+	var errs []error
+
+	for i, factory := range factories {
+		c, err := factory()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("alice: factory #%d: %w", i, err))
+			continue
+		}
+		constructors = append(constructors, c)
+	}
+
+	if len(errs) > 0 {
+		return Chain{}, errors.Join(errs...)
+	}
+
+	return New(constructors...), nil
+}
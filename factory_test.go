@@ -0,0 +1,56 @@
+package alice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func factoryOf(c Constructor, err error) func() (Constructor, error) {
+	return func() (Constructor, error) {
+		return c, err
+	}
+}
+
+func TestNewFromFactoriesBuildsChainCorrectly(t *testing.T) {
+	chain, err := NewFromFactories(
+		factoryOf(tagMiddleware("t1\n"), nil),
+		factoryOf(tagMiddleware("t2\n"), nil),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\napp\n" {
+		t.Error("NewFromFactories does not build the chain correctly")
+	}
+}
+
+func TestNewFromFactoriesReportsFailingFactories(t *testing.T) {
+	errBroken := errors.New("broken")
+	errInvalid := errors.New("invalid")
+
+	_, err := NewFromFactories(
+		factoryOf(tagMiddleware(""), nil),
+		factoryOf(nil, errBroken),
+		factoryOf(nil, errInvalid),
+	)
+	if err == nil {
+		t.Fatal("NewFromFactories should return an error")
+	}
+	if !errors.Is(err, errBroken) || !errors.Is(err, errInvalid) {
+		t.Error("NewFromFactories should wrap the factory errors")
+	}
+	if err.Error() != "alice: factory #1: broken\nalice: factory #2: invalid" {
+		t.Errorf("NewFromFactories does not report the failing factories correctly: %v", err)
+	}
+}
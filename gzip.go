@@ -0,0 +1,190 @@
+package alice
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultGzipSkip lists the content types Gzip does not compress.
+var defaultGzipSkip = []string{
+	"image/", "video/", "audio/",
+	"application/gzip", "application/zip", "application/x-gzip",
+}
+
+// Gzip returns a constructor for middleware
+// compressing responses with gzip at the given level
+// for clients accepting the gzip encoding.
+//
+// Responses whose Content-Type starts with image/, video/, audio/
+// or one of the usual archive types are not compressed,
+// and neither are responses already having a Content-Encoding.
+//
+// Gzip panics if level is not a valid compress/gzip level.
+//
+//	alice.New(alice.Gzip(gzip.DefaultCompression)).Then(h)
+func Gzip(level int) Constructor {
+	return GzipSkip(level, defaultGzipSkip)
+}
+
+// GzipSkip works like Gzip,
+// but leaves uncompressed the responses whose Content-Type
+// starts with one of skip instead.
+//
+//	alice.New(alice.GzipSkip(gzip.DefaultCompression, []string{"image/"})).Then(h)
+func GzipSkip(level int, skip []string) Constructor {
+	if _, err := gzip.NewWriterLevel(nil, level); err != nil {
+		panic("alice: invalid level for Gzip")
+	}
+	skip = append(([]string)(nil), skip...)
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipWriter{ResponseWriter: w, level: level, skip: skip}
+			defer gw.close()
+			h.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r
+// allows the gzip encoding, that is lists it with a qvalue other than 0.
+// A qvalue that cannot be parsed counts as a refusal.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(enc, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		return qvalue(params) > 0
+	}
+	return false
+}
+
+// qvalue returns the q parameter of an Accept-Encoding element,
+// 1 if it has none, and 0 if it cannot be parsed.
+func qvalue(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		key, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(key), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
+
+// gzipWriter compresses what is written through it.
+// The status code is held back until the first write,
+// or until the handler returns if it writes no body,
+// so that whether to compress is decided knowing the content type.
+type gzipWriter struct {
+	http.ResponseWriter
+	level   int
+	skip    []string
+	code    int
+	started bool
+	gz      *gzip.Writer
+}
+
+// start decides whether to compress the response,
+// given the first body bytes if any,
+// and writes the status code.
+func (w *gzipWriter) start(body []byte, hasBody bool) {
+	w.started = true
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if hasBody && w.compress(body) {
+		header := w.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz, _ = gzip.NewWriterLevel(w.ResponseWriter, w.level)
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+// compress reports whether to compress a response starting with body,
+// sniffing its content type if the handler did not set one,
+// as net/http would.
+func (w *gzipWriter) compress(body []byte) bool {
+	header := w.Header()
+	if w.code == http.StatusNoContent || w.code == http.StatusNotModified ||
+		header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	ct := header.Get("Content-Type")
+	if ct == "" {
+		ct = http.DetectContentType(body)
+		header.Set("Content-Type", ct)
+	}
+	for _, s := range w.skip {
+		if strings.HasPrefix(ct, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	if w.started {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.start(b, true)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.gz.Write(b)
+}
+
+// Flush flushes the compressed data written so far,
+// then the wrapped writer if it is an http.Flusher.
+// Flushing before any write sends the response uncompressed.
+func (w *gzipWriter) Flush() {
+	if !w.started {
+		w.start(nil, false)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close writes the held back status code of responses without body,
+// and finishes compressed ones.
+func (w *gzipWriter) close() {
+	if !w.started && w.code != 0 {
+		w.start(nil, false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
@@ -0,0 +1,128 @@
+package alice
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func gzipRequest(t *testing.T, h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestGzipCompresses(t *testing.T) {
+	chained := New(Gzip(gzip.BestSpeed)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "11")
+		w.Write([]byte("hello world"))
+	})
+
+	w := gzipRequest(t, chained, "deflate, gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatal("Gzip did not compress the response")
+	}
+	if w.Header().Get("Content-Length") != "" {
+		t.Error("Gzip kept the Content-Length of the uncompressed body")
+	}
+
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "hello world" {
+		t.Errorf("decompressed body is %q", body)
+	}
+}
+
+func TestGzipPlaintext(t *testing.T) {
+	chained := New(Gzip(gzip.BestSpeed)).Then(testApp)
+
+	for _, enc := range []string{"", "gzip;q=0", "gzip;q=0.0", "GZIP; Q=0.000", "gzip;q=zero"} {
+		w := gzipRequest(t, chained, enc)
+		if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "app\n" {
+			t.Errorf("Gzip compressed for Accept-Encoding %q", enc)
+		}
+	}
+}
+
+func TestGzipAcceptsQValues(t *testing.T) {
+	chained := New(Gzip(gzip.BestSpeed)).Then(testApp)
+
+	for _, enc := range []string{"GZIP", "gzip;q=0.5", "br;q=1.0, Gzip ; q=0.001"} {
+		w := gzipRequest(t, chained, enc)
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Errorf("Gzip did not compress for Accept-Encoding %q", enc)
+		}
+	}
+}
+
+func TestGzipSkipsCompressedTypes(t *testing.T) {
+	chained := New(Gzip(gzip.BestSpeed)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	})
+
+	w := gzipRequest(t, chained, "gzip")
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "png" {
+		t.Error("Gzip compressed an image")
+	}
+}
+
+func TestGzipAfterWriteHeader(t *testing.T) {
+	chained := New(Gzip(gzip.BestSpeed)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<html><body>created</body></html>"))
+	})
+
+	w := gzipRequest(t, chained, "gzip")
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Gzip responded %d with Content-Encoding %q", w.Code, w.Header().Get("Content-Encoding"))
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type is %q", ct)
+	}
+
+	skipped := New(Gzip(gzip.BestSpeed)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("png"))
+	})
+
+	w = gzipRequest(t, skipped, "gzip")
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "" || w.Body.String() != "png" {
+		t.Error("Gzip compressed an image after WriteHeader")
+	}
+}
+
+func TestGzipWithoutBody(t *testing.T) {
+	chained := New(Gzip(gzip.BestSpeed)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	w := gzipRequest(t, chained, "gzip")
+	if w.Code != http.StatusNoContent || w.Header().Get("Content-Encoding") != "" || w.Body.Len() != 0 {
+		t.Errorf("Gzip responded %d with Content-Encoding %q", w.Code, w.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestGzipSkipUsesGivenTypes(t *testing.T) {
+	chained := New(GzipSkip(gzip.BestSpeed, []string{"text/"})).Then(testApp)
+
+	w := gzipRequest(t, chained, "gzip")
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "app\n" {
+		t.Error("GzipSkip compressed a skipped content type")
+	}
+}
@@ -0,0 +1,28 @@
+package alice
+
+import "net/http"
+
+// SetHeaders returns a constructor for middleware
+// setting the given headers on every response
+// before calling the next handler,
+// which can still override or remove them.
+//
+//	alice.New(alice.SetHeaders(map[string]string{
+//		"X-Frame-Options":        "DENY",
+//		"X-Content-Type-Options": "nosniff",
+//	})).Then(h)
+func SetHeaders(headers map[string]string) Constructor {
+	copied := make(map[string]string, len(headers))
+	for name, value := range headers {
+		copied[name] = value
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range copied {
+				w.Header().Set(name, value)
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetHeadersSetsHeaders(t *testing.T) {
+	headers := map[string]string{
+		"X-Frame-Options": "DENY",
+		"X-Custom":        "default",
+	}
+	chained := New(SetHeaders(headers)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Custom", "overridden")
+		w.Write([]byte("app\n"))
+	})
+	headers["X-Frame-Options"] = "SAMEORIGIN"
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Header().Get("X-Frame-Options") != "DENY" {
+		t.Errorf("SetHeaders does not set headers correctly: %q", w.Header().Get("X-Frame-Options"))
+	}
+	if w.Header().Get("X-Custom") != "overridden" {
+		t.Error("SetHeaders does not let the next handler override headers")
+	}
+}
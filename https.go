@@ -0,0 +1,75 @@
+package alice
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RequireHTTPS returns a constructor for middleware
+// letting only requests that came over TLS through.
+// Other requests are redirected to the https URL,
+// on the default port, if redirect is true,
+// and get a 400 Bad Request response otherwise.
+//
+//	alice.New(alice.RequireHTTPS(true)).Then(h)
+//
+// The X-Forwarded-Proto header is ignored, since clients can send it too;
+// use RequireHTTPSBehindProxy behind a TLS terminating proxy.
+func RequireHTTPS(redirect bool) Constructor {
+	return requireHTTPS(redirect, false)
+}
+
+// RequireHTTPSBehindProxy works like RequireHTTPS, but also lets through
+// requests whose X-Forwarded-Proto header says https.
+// Only use it when every request comes through a proxy setting that header.
+func RequireHTTPSBehindProxy(redirect bool) Constructor {
+	return requireHTTPS(redirect, true)
+}
+
+func requireHTTPS(redirect, trustForwarded bool) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isHTTPS(r, trustForwarded) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if !redirect {
+				http.Error(w, "HTTPS required", http.StatusBadRequest)
+				return
+			}
+
+			u := *r.URL
+			u.Scheme = "https"
+			u.Host = hostWithoutPort(r.Host)
+			permanentRedirect(w, r, u.String())
+		})
+	}
+}
+
+// isHTTPS reports whether r was made over HTTPS,
+// directly or, if trustForwarded is true, through a proxy.
+func isHTTPS(r *http.Request, trustForwarded bool) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if !trustForwarded {
+		return false
+	}
+
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// hostWithoutPort returns host without its port, if any.
+func hostWithoutPort(host string) string {
+	h, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+	if strings.Contains(h, ":") {
+		return "[" + h + "]"
+	}
+	return h
+}
@@ -0,0 +1,65 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireHTTPSBehindProxyLetsHTTPSThrough(t *testing.T) {
+	chained := New(RequireHTTPSBehindProxy(true)).Then(testApp)
+
+	tlsReq := httptest.NewRequest("GET", "https://example.com/", nil)
+	proxiedReq := httptest.NewRequest("GET", "http://example.com/", nil)
+	proxiedReq.Header.Set("X-Forwarded-Proto", "https")
+
+	for _, r := range []*http.Request{tlsReq, proxiedReq} {
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK || w.Body.String() != "app\n" {
+			t.Errorf("RequireHTTPSBehindProxy does not let %s through: %d", r.URL, w.Code)
+		}
+	}
+}
+
+func TestRequireHTTPSIgnoresForwardedProto(t *testing.T) {
+	chained := New(RequireHTTPS(false)).Then(testApp)
+
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("RequireHTTPS should not trust X-Forwarded-Proto: %d", w.Code)
+	}
+}
+
+func TestRequireHTTPSRedirects(t *testing.T) {
+	chained := New(RequireHTTPS(true)).Then(testApp)
+
+	for target, expected := range map[string]string{
+		"http://example.com/a?b=c":  "https://example.com/a?b=c",
+		"http://example.com:8080/x": "https://example.com/x",
+		"http://[::1]:8080/x":       "https://[::1]/x",
+	} {
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != expected {
+			t.Errorf("RequireHTTPS redirected %s with %d to %q", target, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestRequireHTTPSRejects(t *testing.T) {
+	chained := New(RequireHTTPS(false)).Then(testApp)
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("RequireHTTPS should respond 400, got %d", w.Code)
+	}
+}
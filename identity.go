@@ -0,0 +1,105 @@
+package alice
+
+import (
+	"net/http"
+	"reflect"
+	"unsafe"
+)
+
+// sameValue reports whether c1 and c2 are the same func value,
+// which is the case for copies of one value only:
+// separately created closures are different values,
+// even when they were created by the same function literal,
+// and so are separately evaluated method values.
+// Constructors returned by Named and Prioritized are compared
+// by their annotations and the constructor they wrap,
+// unless they have the same name.
+func sameValue(c1, c2 Constructor) bool {
+	return matchConstructors(c1, c2, func(c1, c2 Constructor) bool {
+		return funcValue(c1) == funcValue(c2)
+	})
+}
+
+// funcValue returns the pointer a func value is made of.
+//
+// Comparing code pointers through reflect is not enough to tell values apart:
+// every closure created by the same function literal shares one.
+// A func value is however a pointer to a closure object,
+// which is only shared by copies of the same value.
+func funcValue(c Constructor) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&c))
+}
+
+// sameCode reports whether c1 and c2 are the same function,
+// comparing their code pointers through reflect.Value.Pointer.
+//
+// Code pointers tell functions apart, not func values:
+// every closure created by the same function literal shares one,
+// whatever it captured, and so does every method value
+// of the same method, whatever its receiver.
+// Constructors returned by Named and Prioritized all share one too,
+// so they are compared by the constructor they wrap
+// and their annotations, unless they have the same name.
+func sameCode(c1, c2 Constructor) bool {
+	return matchConstructors(c1, c2, func(c1, c2 Constructor) bool {
+		return reflect.ValueOf(c1).Pointer() == reflect.ValueOf(c2).Pointer()
+	})
+}
+
+// matchConstructors reports whether c1 and c2 are the same
+// according to same, which compares constructors that are not annotated.
+// Annotated constructors match when they were given the same name with Named,
+// or when they have the same annotations and wrap matching constructors.
+func matchConstructors(c1, c2 Constructor, same func(c1, c2 Constructor) bool) bool {
+	if !isAnnotated(c1) && !isAnnotated(c2) {
+		return same(c1, c2)
+	}
+	if !isAnnotated(c1) || !isAnnotated(c2) {
+		return false
+	}
+
+	a1, inner1 := unannotate(c1)
+	a2, inner2 := unannotate(c2)
+	if a1.hasName && a2.hasName && a1.name == a2.name {
+		return true
+	}
+
+	return a1 == a2 && same(inner1, inner2)
+}
+
+// sameHandler reports whether h1 and h2 are the same handler.
+// Handlers whose dynamic type is a func are compared by value, as sameValue does,
+// those whose dynamic type is a pointer or similar by pointer,
+// others by interface equality, handlers that cannot be compared
+// being different.
+func sameHandler(h1, h2 http.Handler) (same bool) {
+	if h1 == nil || h2 == nil {
+		return h1 == nil && h2 == nil
+	}
+
+	v1, v2 := reflect.ValueOf(h1), reflect.ValueOf(h2)
+	if v1.Type() != v2.Type() {
+		return false
+	}
+	switch v1.Kind() {
+	case reflect.Func:
+		return handlerFuncValue(v1) == handlerFuncValue(v2)
+	case reflect.Ptr, reflect.Chan, reflect.Map, reflect.UnsafePointer:
+		return v1.Pointer() == v2.Pointer()
+	}
+
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return h1 == h2
+}
+
+// handlerFuncValue returns the pointer the func value held by v is made of,
+// as funcValue does for constructors.
+func handlerFuncValue(v reflect.Value) unsafe.Pointer {
+	addressable := reflect.New(v.Type()).Elem()
+	addressable.Set(v)
+	return *(*unsafe.Pointer)(unsafe.Pointer(addressable.UnsafeAddr()))
+}
@@ -0,0 +1,74 @@
+package alice
+
+import (
+	"net/http"
+	"testing"
+)
+
+type throttler struct{}
+
+func (*throttler) Throttle(h http.Handler) http.Handler {
+	return h
+}
+
+func TestSameValue(t *testing.T) {
+	c1 := Constructor(t2Middleware)
+	copied := c1
+
+	if !sameValue(c1, copied) {
+		t.Error("sameValue should be true for copies of a constructor")
+	}
+	if sameValue(t1Middleware, t2Middleware) {
+		t.Error("sameValue should be false for different functions")
+	}
+	if sameValue(tagMiddleware("t1\n"), tagMiddleware("t1\n")) {
+		t.Error("sameValue should be false for separately created closures")
+	}
+	th1, th2 := new(throttler), new(throttler)
+	if sameValue(th1.Throttle, th2.Throttle) {
+		t.Error("sameValue should be false for method values of different receivers")
+	}
+	if !sameValue(Named("a", tagMiddleware("t1\n")), Named("a", tagMiddleware("t2\n"))) {
+		t.Error("sameValue should be true for constructors with the same name")
+	}
+}
+
+func TestSameCode(t *testing.T) {
+	c1 := Constructor(t2Middleware)
+	copied := c1
+
+	if !sameCode(c1, copied) {
+		t.Error("sameCode should be true for copies of a constructor")
+	}
+	if sameCode(t1Middleware, t2Middleware) {
+		t.Error("sameCode should be false for different functions")
+	}
+	if !sameCode(tagMiddleware("t1\n"), tagMiddleware("t2\n")) {
+		t.Error("sameCode should be true for closures of the same function literal")
+	}
+	th1, th2 := new(throttler), new(throttler)
+	if !sameCode(th1.Throttle, th2.Throttle) {
+		t.Error("sameCode should be true for method values of the same method")
+	}
+	if !sameCode(nil, nil) || sameCode(c1, nil) {
+		t.Error("sameCode does not handle nil correctly")
+	}
+}
+
+func TestSameCodeComparesAnnotations(t *testing.T) {
+	if !sameCode(Named("a", t1Middleware), Named("a", t2Middleware)) {
+		t.Error("sameCode should be true for constructors with the same name")
+	}
+	if sameCode(Named("a", t1Middleware), Named("b", t1Middleware)) {
+		t.Error("sameCode should be false for constructors with different names")
+	}
+	if sameCode(Named("a", t1Middleware), t1Middleware) {
+		t.Error("sameCode should be false for a named and an unnamed constructor")
+	}
+	if sameCode(Prioritized(1, t1Middleware), Prioritized(1, t2Middleware)) {
+		t.Error("sameCode should be false for annotated constructors wrapping different functions")
+	}
+	if !sameCode(Prioritized(1, t1Middleware), Prioritized(1, t1Middleware)) {
+		t.Error("sameCode should be true for annotated constructors wrapping the same function")
+	}
+}
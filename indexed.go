@@ -0,0 +1,44 @@
+package alice
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// IndexedConstructor is a constructor for middleware
+// that depends on its position in the chain.
+// index is 0 for the outermost middleware
+// and total-1 for the innermost one.
+type IndexedConstructor func(next http.Handler, index, total int) http.Handler
+
+// Indexed adapts an IndexedConstructor to a Constructor.
+//
+// When the chain is built, the constructor receives
+// its final position in the request flow,
+// taking Append(), Prepend(), Reversed() and friends into account.
+// The position is also known when the constructor is wrapped with Named or Prioritized,
+// but not when it is wrapped in other helpers,
+// or called on its own: it then receives an index of 0 and a total of 1.
+//
+//	alice.New(m1, alice.Indexed(func(h http.Handler, index, total int) http.Handler {
+//		return logger(h, fmt.Sprintf("stage %d/%d", index+1, total))
+//	}))
+//
+//go:noinline
+func Indexed(c IndexedConstructor) Constructor {
+	// The returned closure is recognized by acceptsBuildProbe through its code pointer,
+	// which is why Indexed must not be inlined.
+	return func(h http.Handler) http.Handler {
+		if p, ok := h.(*buildProbe); ok {
+			return c(p.next, p.index, p.total)
+		}
+		return c(h, 0, 1)
+	}
+}
+
+// indexedPointer is the code pointer shared by all constructors returned by Indexed.
+var indexedPointer uintptr
+
+func init() {
+	indexedPointer = reflect.ValueOf(Indexed(nil)).Pointer()
+}
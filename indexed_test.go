@@ -0,0 +1,83 @@
+package alice
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// An indexed constructor for middleware
+// that writes its position into the RW and does nothing else.
+var positionMiddleware = Indexed(func(h http.Handler, index, total int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%d/%d\n", index, total)
+		h.ServeHTTP(w, r)
+	})
+})
+
+func TestIndexedReceivesPositionsCorrectly(t *testing.T) {
+	tests := []struct {
+		chain    Chain
+		expected string
+	}{
+		{
+			New(positionMiddleware, tagMiddleware("t1\n"), positionMiddleware),
+			"0/3\nt1\n2/3\napp\n",
+		},
+		{
+			New(positionMiddleware, positionMiddleware).Prepend(tagMiddleware("t1\n")),
+			"t1\n1/3\n2/3\napp\n",
+		},
+		{
+			New(positionMiddleware, tagMiddleware("t1\n"), tagMiddleware("t2\n")).Reversed(),
+			"t2\nt1\n2/3\napp\n",
+		},
+		{
+			New(tagMiddleware("t1\n"), Named("position", positionMiddleware)),
+			"t1\n1/2\napp\n",
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		test.chain.Then(testApp).ServeHTTP(w, r)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("Indexed does not receive positions correctly for %s: %q", test.chain, w.Body.String())
+		}
+	}
+}
+
+func TestIndexedCalledOnItsOwn(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	positionMiddleware(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "0/1\napp\n" {
+		t.Error("Indexed does not default its position correctly")
+	}
+}
+
+func TestNamedDoesNotLeakBuildProbe(t *testing.T) {
+	app := http.NewServeMux()
+
+	h := New(tagMiddleware(""), Named("identity", Identity)).Then(app)
+	if _, ok := h.(*buildProbe); ok {
+		t.Error("Named should not pass the build probe to unaware constructors")
+	}
+
+	h = New(Named("identity", Identity)).Then(app)
+	if h != app {
+		t.Error("Named should build unaware constructors around the next handler")
+	}
+}
@@ -0,0 +1,48 @@
+package alice
+
+import "net/http"
+
+// MaxInFlight returns a constructor for middleware
+// allowing at most n requests to be served at the same time
+// by the next handler.
+// Requests over the limit are not queued:
+// they get a 503 Service Unavailable response.
+//
+// Each handler built from the constructor has its own limit.
+// MaxInFlight panics if n is not positive.
+//
+//	alice.New(alice.MaxInFlight(100)).Then(h)
+func MaxInFlight(n int) Constructor {
+	return MaxInFlightOverflow(n, nil)
+}
+
+// MaxInFlightOverflow works like MaxInFlight,
+// but serves requests over the limit with overflow.
+// A nil overflow responds 503 Service Unavailable.
+//
+//	alice.New(alice.MaxInFlightOverflow(100, busyPage)).Then(h)
+func MaxInFlightOverflow(n int, overflow http.Handler) Constructor {
+	if n <= 0 {
+		panic("alice: non-positive limit for MaxInFlight")
+	}
+
+	full := overflow
+	if full == nil {
+		full = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		})
+	}
+
+	return func(h http.Handler) http.Handler {
+		sem := make(chan struct{}, n)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				h.ServeHTTP(w, r)
+			default:
+				full.ServeHTTP(w, r)
+			}
+		})
+	}
+}
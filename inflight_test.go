@@ -0,0 +1,84 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestMaxInFlightLimitsConcurrentRequests(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	chained := New(MaxInFlight(2)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		w.Write([]byte("app\n"))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chained.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}()
+		<-entered
+	}
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("MaxInFlight should reject requests over the limit, got %d", w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+
+	go func() {
+		<-entered
+	}()
+	w = httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "app\n" {
+		t.Errorf("MaxInFlight should serve requests once capacity frees up, got %d", w.Code)
+	}
+}
+
+func TestMaxInFlightUsesOverflowHandler(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	overflow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	chained := New(MaxInFlightOverflow(1, overflow)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		chained.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	<-entered
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("MaxInFlight should use the overflow handler, got %d", w.Code)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestMaxInFlightPanicsOnNonPositiveLimit(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MaxInFlight should panic on a non-positive limit")
+		}
+	}()
+
+	MaxInFlight(0)
+}
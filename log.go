@@ -0,0 +1,28 @@
+package alice
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Log returns a constructor for middleware
+// logging the method, path, response status and duration
+// of each request with logf.
+// A nil logf logs with log.Printf.
+//
+//	alice.New(alice.Log(nil)).Then(h)
+func Log(logf func(format string, args ...interface{})) Constructor {
+	if logf == nil {
+		logf = log.Printf
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := NewStatusRecorder(w)
+			start := time.Now()
+			h.ServeHTTP(sw, r)
+			logf("%s %s %d %s", r.Method, r.URL.Path, sw.Status(), time.Since(start))
+		})
+	}
+}
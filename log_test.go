@@ -0,0 +1,39 @@
+package alice
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogLogsStatus(t *testing.T) {
+	var line string
+	logf := func(format string, args ...interface{}) {
+		line = fmt.Sprintf(format, args...)
+	}
+
+	chained := New(Log(logf)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	chained.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/users", nil))
+
+	if !strings.HasPrefix(line, "POST /users 201 ") {
+		t.Errorf("Log logged %q", line)
+	}
+}
+
+func TestLogDefaultsToStatusOK(t *testing.T) {
+	var line string
+	logf := func(format string, args ...interface{}) {
+		line = fmt.Sprintf(format, args...)
+	}
+
+	chained := New(Log(logf)).Then(testApp)
+	chained.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if !strings.HasPrefix(line, "GET / 200 ") {
+		t.Errorf("Log logged %q", line)
+	}
+}
@@ -0,0 +1,32 @@
+package alice
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AllowMethods returns a constructor for middleware
+// letting through only requests using one of the given methods,
+// compared case-insensitively like OnMethods does.
+// Other requests get a 405 Method Not Allowed response
+// with an Allow header listing the methods.
+//
+//	alice.New(alice.AllowMethods("GET", "HEAD")).Then(h)
+func AllowMethods(methods ...string) Constructor {
+	methods = append(([]string)(nil), methods...)
+	allow := strings.Join(methods, ", ")
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, method := range methods {
+				if strings.EqualFold(r.Method, method) {
+					h.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			w.Header().Set("Allow", allow)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowMethodsRejectsOtherMethods(t *testing.T) {
+	chained := New(AllowMethods("GET", "HEAD")).Then(testApp)
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("AllowMethods responded %d with Allow %q", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestAllowMethodsLetsAllowedMethodsThrough(t *testing.T) {
+	chained := New(AllowMethods("GET", "HEAD")).Then(testApp)
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "app\n" {
+		t.Errorf("AllowMethods does not let GET through: %d", w.Code)
+	}
+}
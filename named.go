@@ -0,0 +1,114 @@
+package alice
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Named returns a constructor that behaves exactly like c,
+// but whose name can be read back with Name.
+//
+// Names make chains easier to inspect and manipulate:
+// they show up in Chain.String().
+//
+//	logger := alice.Named("logger", loggingHandler)
+//	name, _ := alice.Name(logger) // "logger"
+func Named(name string, c Constructor) Constructor {
+	return annotate(c, annotation{name: name, hasName: true})
+}
+
+// Name returns the name given to c by Named.
+// The boolean is false if c was not created by Named.
+func Name(c Constructor) (string, bool) {
+	a := annotations(c)
+	return a.name, a.hasName
+}
+
+// annotation holds the metadata attached to a constructor
+// by Named and similar helpers.
+type annotation struct {
+	name        string
+	hasName     bool
+	priority    int
+	hasPriority bool
+}
+
+// annotate returns a constructor that behaves exactly like c,
+// annotated with a.
+// Annotations can be nested, the outermost ones taking precedence.
+//
+//go:noinline
+func annotate(c Constructor, a annotation) Constructor {
+	// The returned closure is recognized through its code pointer,
+	// which is why annotate must not be inlined.
+	return func(h http.Handler) http.Handler {
+		switch p := h.(type) {
+		case *annotationProbe:
+			p.merge(a)
+			if isAnnotated(c) {
+				c(p)
+			} else {
+				p.inner = c
+			}
+			return h
+		case *buildProbe:
+			if !acceptsBuildProbe(c) {
+				h = p.next
+			}
+		}
+		return c(h)
+	}
+}
+
+// annotatedPointer is the code pointer shared by all constructors returned by annotate.
+var annotatedPointer uintptr
+
+func init() {
+	annotatedPointer = reflect.ValueOf(annotate(nil, annotation{})).Pointer()
+}
+
+// isAnnotated reports whether c was returned by annotate.
+func isAnnotated(c Constructor) bool {
+	return c != nil && reflect.ValueOf(c).Pointer() == annotatedPointer
+}
+
+// annotations returns the metadata attached to c,
+// without building any middleware.
+func annotations(c Constructor) annotation {
+	a, _ := unannotate(c)
+	return a
+}
+
+// unannotate returns the metadata attached to c,
+// along with the innermost constructor it wraps,
+// without building any middleware.
+// Constructors that are not annotated are returned as is.
+func unannotate(c Constructor) (annotation, Constructor) {
+	if !isAnnotated(c) {
+		return annotation{}, c
+	}
+
+	p := &annotationProbe{}
+	c(p)
+
+	return p.annotation, p.inner
+}
+
+// annotationProbe is passed to annotated constructors
+// to make them report their metadata instead of building middleware.
+type annotationProbe struct {
+	annotation
+	inner Constructor
+}
+
+// merge adds the metadata of a which is not already known to p.
+func (p *annotationProbe) merge(a annotation) {
+	if a.hasName && !p.hasName {
+		p.name, p.hasName = a.name, true
+	}
+	if a.hasPriority && !p.hasPriority {
+		p.priority, p.hasPriority = a.priority, true
+	}
+}
+
+func (*annotationProbe) ServeHTTP(http.ResponseWriter, *http.Request) {}
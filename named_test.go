@@ -0,0 +1,47 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNameReturnsNamesCorrectly(t *testing.T) {
+	c := Named("t1", tagMiddleware("t1\n"))
+
+	if name, ok := Name(c); !ok || name != "t1" {
+		t.Errorf("Name should return t1, got %q", name)
+	}
+	if _, ok := Name(tagMiddleware("")); ok {
+		t.Error("Name should not find a name for an unnamed constructor")
+	}
+	if _, ok := Name(nil); ok {
+		t.Error("Name should not find a name for nil")
+	}
+}
+
+func TestNameDoesNotBuildConstructor(t *testing.T) {
+	builds := 0
+	Name(Named("counter", countingMiddleware(&builds)))
+	Name(countingMiddleware(&builds))
+
+	if builds != 0 {
+		t.Error("Name should not call the constructor")
+	}
+}
+
+func TestNamedBehavesLikeConstructor(t *testing.T) {
+	chained := New(Named("t1", tagMiddleware("t1\n")), tagMiddleware("t2\n")).Then(testApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\napp\n" {
+		t.Error("Named does not behave like the named constructor")
+	}
+}
@@ -0,0 +1,93 @@
+package alice
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// StripPrefix returns a constructor for middleware
+// removing prefix from the request URL path
+// before calling the next handler, as http.StripPrefix does.
+// Requests whose path does not start with prefix
+// get a 404 Not Found response.
+//
+//	alice.New(alice.StripPrefix("/api")).Then(apiMux)
+func StripPrefix(prefix string) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.StripPrefix(prefix, h)
+	}
+}
+
+// CleanPath returns a constructor for middleware
+// cleaning the request URL path with path.Clean,
+// collapsing duplicate slashes and resolving . and .. elements,
+// while keeping any trailing slash.
+// Requests for an unclean path are passed on with the clean path.
+//
+//	alice.New(alice.CleanPath()).Then(mux)
+func CleanPath() Constructor {
+	return cleanPathConstructor(false)
+}
+
+// CleanPathRedirect works like CleanPath,
+// but redirects requests for an unclean path to the clean one
+// instead of passing them on.
+//
+//	alice.New(alice.CleanPathRedirect()).Then(mux)
+func CleanPathRedirect() Constructor {
+	return cleanPathConstructor(true)
+}
+
+// cleanPathConstructor returns the constructor of CleanPath,
+// or of CleanPathRedirect if redirecting is true.
+func cleanPathConstructor(redirecting bool) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clean := cleanPath(r.URL.Path)
+			if clean == r.URL.Path {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			u := *r.URL
+			u.Path = clean
+			u.RawPath = ""
+			if redirecting {
+				permanentRedirect(w, r, u.String())
+				return
+			}
+
+			r = r.WithContext(r.Context())
+			r.URL = &u
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// cleanPath returns the canonical form of p,
+// keeping its trailing slash, like http.ServeMux does.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+
+	clean := path.Clean(p)
+	if strings.HasSuffix(p, "/") && clean != "/" {
+		clean += "/"
+	}
+	return clean
+}
+
+// permanentRedirect redirects r to url permanently,
+// with a status code keeping the method of requests other than GET and HEAD.
+func permanentRedirect(w http.ResponseWriter, r *http.Request, url string) {
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	http.Redirect(w, r, url, code)
+}
@@ -0,0 +1,67 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripPrefixStripsPath(t *testing.T) {
+	var path string
+	chained := New(StripPrefix("/api")).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/api/users", nil))
+
+	if w.Code != http.StatusOK || path != "/users" {
+		t.Errorf("StripPrefix does not strip the path: %d %q", w.Code, path)
+	}
+}
+
+func TestStripPrefixRejectsOtherPaths(t *testing.T) {
+	called := false
+	chained := New(StripPrefix("/api")).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+
+	if w.Code != http.StatusNotFound || called {
+		t.Errorf("StripPrefix should respond 404 to other paths, got %d", w.Code)
+	}
+}
+
+func TestCleanPathRewrites(t *testing.T) {
+	var path string
+	chained := New(CleanPath()).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	})
+
+	for target, expected := range map[string]string{
+		"//a//b/../c": "/a/c",
+		"/a/./b/":     "/a/b/",
+		"/a/c":        "/a/c",
+	} {
+		chained.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+		if path != expected {
+			t.Errorf("CleanPath rewrote %q to %q, expected %q", target, path, expected)
+		}
+	}
+}
+
+func TestCleanPathRedirects(t *testing.T) {
+	called := false
+	chained := New(CleanPathRedirect()).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "//a//b/../c?d=e", nil))
+
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/a/c?d=e" || called {
+		t.Errorf("CleanPath redirected with %d to %q", w.Code, w.Header().Get("Location"))
+	}
+}
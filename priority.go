@@ -0,0 +1,63 @@
+package alice
+
+import "sort"
+
+// Prioritized returns a constructor that behaves exactly like c,
+// but carries a priority, used by Chain.SortByPriority.
+// Constructors that were not given one have a priority of 0.
+//
+// Priorities let independently registered middleware,
+// such as plugins, declare where they belong in a chain
+// without knowing about each other.
+func Prioritized(priority int, c Constructor) Constructor {
+	return annotate(c, annotation{priority: priority, hasPriority: true})
+}
+
+// Priority returns the priority given to c by Prioritized.
+// The boolean is false if c was not given a priority.
+func Priority(c Constructor) (int, bool) {
+	a := annotations(c)
+	return a.priority, a.hasPriority
+}
+
+// SortByPriority returns a new chain holding the same constructors,
+// sorted by ascending priority:
+// the lower its priority, the earlier a constructor is in the request flow.
+// Constructors with equal priorities keep their relative order.
+// The original chain is left untouched.
+//
+//	late := alice.Prioritized(10, m1)
+//	early := alice.Prioritized(-10, m2)
+//	alice.New(late, m3, early).SortByPriority()
+//	// requests go m2 -> m3 -> m1
+func (c Chain) SortByPriority() Chain {
+	sorted := byPriority{
+		constructors: append(([]Constructor)(nil), c.constructors...),
+		priorities:   make([]int, len(c.constructors)),
+	}
+	for i, constructor := range c.constructors {
+		sorted.priorities[i], _ = Priority(constructor)
+	}
+	sort.Stable(sorted)
+
+	return c.derive(sorted.constructors)
+}
+
+// byPriority sorts constructors by their priorities.
+type byPriority struct {
+	constructors []Constructor
+	priorities   []int
+}
+
+func (p byPriority) Len() int {
+	return len(p.constructors)
+}
+
+func (p byPriority) Less(i, j int) bool {
+	return p.priorities[i] < p.priorities[j]
+}
+
+func (p byPriority) Swap(i, j int) {
+	p.constructors[i], p.constructors[j] = p.constructors[j], p.constructors[i]
+	p.priorities[i], p.priorities[j] = p.priorities[j], p.priorities[i]
+}
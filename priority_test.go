@@ -0,0 +1,55 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPriorityReturnsPrioritiesCorrectly(t *testing.T) {
+	if p, ok := Priority(Prioritized(5, tagMiddleware(""))); !ok || p != 5 {
+		t.Errorf("Priority should return 5, got %d", p)
+	}
+	if _, ok := Priority(tagMiddleware("")); ok {
+		t.Error("Priority should not find a priority for an unprioritized constructor")
+	}
+
+	c := Named("t1", Prioritized(5, tagMiddleware("")))
+	if p, ok := Priority(c); !ok || p != 5 {
+		t.Error("Priority should see through Named")
+	}
+	if name, ok := Name(c); !ok || name != "t1" {
+		t.Error("Name should be kept by Prioritized")
+	}
+	if name, ok := Name(Prioritized(5, Named("t1", tagMiddleware("")))); !ok || name != "t1" {
+		t.Error("Name should see through Prioritized")
+	}
+}
+
+func TestSortByPriorityOrdersHandlersCorrectly(t *testing.T) {
+	chain := New(
+		Prioritized(10, tagMiddleware("late\n")),
+		tagMiddleware("t1\n"),
+		Prioritized(-10, tagMiddleware("early1\n")),
+		tagMiddleware("t2\n"),
+		Prioritized(-10, tagMiddleware("early2\n")),
+	)
+	newChain := chain.SortByPriority()
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "late\nt1\nearly1\nt2\nearly2\napp\n" {
+		t.Error("SortByPriority modifies the original chain")
+	}
+
+	w = httptest.NewRecorder()
+	newChain.Then(testApp).ServeHTTP(w, r)
+	if w.Body.String() != "early1\nearly2\nt1\nt2\nlate\napp\n" {
+		t.Errorf("SortByPriority does not order handlers correctly: %q", w.Body.String())
+	}
+}
@@ -0,0 +1,110 @@
+package alice
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit returns a constructor for middleware
+// limiting requests with a token bucket per key:
+// each key can make burst requests at once,
+// then rps requests per second.
+// Requests over the limit get a 429 Too Many Requests response
+// with a Retry-After header.
+//
+// keyFn returns the key of a request;
+// a nil keyFn uses the client IP address.
+// Each handler built from the constructor has its own buckets,
+// and forgets keys once their bucket is full again.
+// RateLimit panics if rps or burst is not positive.
+//
+//	alice.New(alice.RateLimit(10, 20, nil)).Then(h)
+func RateLimit(rps float64, burst int, keyFn func(*http.Request) string) Constructor {
+	if rps <= 0 || burst <= 0 {
+		panic("alice: non-positive rate for RateLimit")
+	}
+	if keyFn == nil {
+		keyFn = clientIP
+	}
+
+	return func(h http.Handler) http.Handler {
+		l := &limiter{
+			rate:    rps,
+			burst:   float64(burst),
+			idle:    time.Duration(float64(burst) / rps * float64(time.Second)),
+			buckets: make(map[string]*bucket),
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if wait := l.take(keyFn(r), time.Now()); wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the IP address of the client making r.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// limiter holds the token buckets of RateLimit.
+type limiter struct {
+	rate  float64
+	burst float64
+	// idle is how long an empty bucket takes to fill up,
+	// after which it can be forgotten.
+	idle time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token from the bucket of key at now,
+// returning how long to wait for one if the bucket is empty.
+func (l *limiter) take(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) > l.idle {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > l.idle {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	b.tokens--
+	return 0
+}
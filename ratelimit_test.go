@@ -0,0 +1,61 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func rateLimited(h http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestRateLimitLimitsRequests(t *testing.T) {
+	chained := New(RateLimit(1, 2, nil)).Then(testApp)
+
+	for i := 0; i < 2; i++ {
+		if w := rateLimited(chained, "192.0.2.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("request #%d within the burst got %d", i, w.Code)
+		}
+	}
+
+	w := rateLimited(chained, "192.0.2.1:5678")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("request over the limit got %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	if w := rateLimited(chained, "192.0.2.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("RateLimit limits other clients, got %d", w.Code)
+	}
+}
+
+func TestRateLimitRefills(t *testing.T) {
+	chained := New(RateLimit(50, 1, nil)).Then(testApp)
+
+	rateLimited(chained, "192.0.2.1:1234")
+	if w := rateLimited(chained, "192.0.2.1:1234"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the limit got %d", w.Code)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if w := rateLimited(chained, "192.0.2.1:1234"); w.Code != http.StatusOK {
+		t.Errorf("RateLimit does not refill, got %d", w.Code)
+	}
+}
+
+func TestLimiterForgetsIdleKeys(t *testing.T) {
+	l := &limiter{rate: 1, burst: 1, idle: time.Second, buckets: make(map[string]*bucket)}
+	now := time.Now()
+
+	l.take("a", now)
+	l.take("b", now.Add(2*time.Second))
+
+	if _, ok := l.buckets["a"]; ok || len(l.buckets) != 1 {
+		t.Errorf("limiter keeps idle keys: %v", l.buckets)
+	}
+}
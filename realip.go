@@ -0,0 +1,92 @@
+package alice
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP returns a constructor for middleware
+// setting the RemoteAddr of requests made through trusted proxies
+// to the IP address of the client, as found in
+// their X-Forwarded-For or X-Real-IP header, with port 0.
+// trustedProxies lists the proxies as IP addresses or CIDR prefixes.
+//
+// Headers are only looked at when the direct peer is a trusted proxy.
+// X-Forwarded-For is read from the right,
+// skipping the addresses of trusted proxies,
+// so that addresses made up by clients are ignored.
+// Requests with a malformed header keep their RemoteAddr.
+//
+// RealIP panics if an entry of trustedProxies is invalid.
+//
+//	alice.New(alice.RealIP([]string{"10.0.0.0/8"})).Then(h)
+func RealIP(trustedProxies []string) Constructor {
+	trusted := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, p := range trustedProxies {
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(p)
+			if addrErr != nil {
+				panic("alice: invalid trusted proxy for RealIP: " + p)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		trusted = append(trusted, prefix.Masked())
+	}
+
+	isTrusted := func(addr netip.Addr) bool {
+		addr = addr.Unmap()
+		for _, p := range trusted {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer, err := netip.ParseAddr(clientIP(r))
+			if err != nil || !isTrusted(peer) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if ip, ok := forwardedFor(r, isTrusted); ok {
+				r = r.WithContext(r.Context())
+				r.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedFor returns the client IP address
+// given by the proxy headers of r,
+// trusting only the proxies for which isTrusted returns true.
+func forwardedFor(r *http.Request, isTrusted func(netip.Addr) bool) (netip.Addr, bool) {
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	if len(hops) == 0 {
+		ip, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+		return ip, err == nil
+	}
+
+	var ip netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		ip = hop
+		if !isTrusted(hop) {
+			break
+		}
+	}
+	return ip, true
+}
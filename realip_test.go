@@ -0,0 +1,58 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func realIP(t *testing.T, remoteAddr string, header http.Header) string {
+	t.Helper()
+
+	var got string
+	chained := New(RealIP([]string{"10.0.0.0/8", "192.0.2.1"})).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = remoteAddr
+	r.Header = header
+	chained.ServeHTTP(httptest.NewRecorder(), r)
+	return got
+}
+
+func TestRealIPTrustedProxies(t *testing.T) {
+	header := http.Header{"X-Forwarded-For": {"203.0.113.9, 198.51.100.7", "10.1.2.3"}}
+	if got := realIP(t, "192.0.2.1:1234", header); got != "198.51.100.7:0" {
+		t.Errorf("RealIP resolved %q", got)
+	}
+
+	header = http.Header{"X-Forwarded-For": {"10.1.2.3"}}
+	if got := realIP(t, "10.0.0.1:1234", header); got != "10.1.2.3:0" {
+		t.Errorf("RealIP resolved %q through trusted proxies only", got)
+	}
+
+	header = http.Header{"X-Real-Ip": {"198.51.100.7"}}
+	if got := realIP(t, "10.0.0.1:1234", header); got != "198.51.100.7:0" {
+		t.Errorf("RealIP resolved %q from X-Real-IP", got)
+	}
+}
+
+func TestRealIPUntrustedPeer(t *testing.T) {
+	header := http.Header{"X-Forwarded-For": {"198.51.100.7"}, "X-Real-Ip": {"198.51.100.7"}}
+	if got := realIP(t, "203.0.113.9:1234", header); got != "203.0.113.9:1234" {
+		t.Errorf("RealIP trusted the headers of an untrusted peer: %q", got)
+	}
+}
+
+func TestRealIPMalformedHeaders(t *testing.T) {
+	for _, header := range []http.Header{
+		{"X-Forwarded-For": {"198.51.100.7, not-an-ip"}},
+		{"X-Forwarded-For": {""}},
+		{"X-Real-Ip": {"not-an-ip"}},
+	} {
+		if got := realIP(t, "10.0.0.1:1234", header); got != "10.0.0.1:1234" {
+			t.Errorf("RealIP resolved %q from %v", got, header)
+		}
+	}
+}
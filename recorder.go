@@ -0,0 +1,84 @@
+package alice
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// StatusRecorder is an http.ResponseWriter
+// recording the status code and the number of bytes
+// written through it to the wrapped writer,
+// for middleware observing responses.
+//
+// Flush and Hijack are passed through
+// when the wrapped writer supports them.
+type StatusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// NewStatusRecorder returns a StatusRecorder wrapping w.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w}
+}
+
+// WriteHeader records code and writes it to the wrapped writer.
+func (r *StatusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes b to the wrapped writer,
+// recording a 200 status if none was written before.
+func (r *StatusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Status returns the recorded status code,
+// which is 200 when nothing was written yet.
+func (r *StatusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// BytesWritten returns the number of body bytes written.
+func (r *StatusRecorder) BytesWritten() int64 {
+	return r.bytes
+}
+
+// Flush flushes the wrapped writer if it is an http.Flusher,
+// and does nothing otherwise.
+func (r *StatusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		if r.status == 0 {
+			r.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection of the wrapped writer
+// if it is an http.Hijacker, and returns an error otherwise.
+func (r *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("alice: wrapped ResponseWriter does not support hijacking")
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
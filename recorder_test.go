@@ -0,0 +1,59 @@
+package alice
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusRecorderDefaultsToStatusOK(t *testing.T) {
+	rec := NewStatusRecorder(httptest.NewRecorder())
+	rec.Write([]byte("hello"))
+
+	if rec.Status() != http.StatusOK || rec.BytesWritten() != 5 {
+		t.Errorf("StatusRecorder recorded %d, %d bytes", rec.Status(), rec.BytesWritten())
+	}
+}
+
+func TestStatusRecorderRecordsStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := NewStatusRecorder(w)
+	rec.WriteHeader(http.StatusTeapot)
+	rec.Write([]byte("hello"))
+
+	if rec.Status() != http.StatusTeapot || w.Code != http.StatusTeapot {
+		t.Errorf("StatusRecorder recorded %d, wrote %d", rec.Status(), w.Code)
+	}
+}
+
+func TestStatusRecorderFlushes(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewStatusRecorder(w).Flush()
+
+	if !w.Flushed {
+		t.Error("StatusRecorder does not pass Flush through")
+	}
+}
+
+type hijackWriter struct {
+	http.ResponseWriter
+	hijacked bool
+}
+
+func (w *hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func TestStatusRecorderHijacks(t *testing.T) {
+	w := &hijackWriter{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := NewStatusRecorder(w).Hijack(); err != nil || !w.hijacked {
+		t.Errorf("StatusRecorder does not pass Hijack through: %v", err)
+	}
+
+	if _, _, err := NewStatusRecorder(httptest.NewRecorder()).Hijack(); err == nil {
+		t.Error("Hijack should fail when the wrapped writer is not a Hijacker")
+	}
+}
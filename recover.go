@@ -0,0 +1,39 @@
+package alice
+
+import "net/http"
+
+// Recover returns a constructor for middleware
+// recovering from panics in the handlers after it,
+// and calling onPanic with the recovered value
+// to respond to the request instead.
+// A nil onPanic responds 500 Internal Server Error.
+//
+// http.ErrAbortHandler is not recovered from,
+// since it is meant to abort the request.
+//
+//	alice.New(alice.Recover(func(w http.ResponseWriter, r *http.Request, v interface{}) {
+//		log.Printf("panic serving %s: %v", r.URL, v)
+//		http.Error(w, "oops", http.StatusInternalServerError)
+//	})).Then(h)
+func Recover(onPanic func(http.ResponseWriter, *http.Request, interface{})) Constructor {
+	if onPanic == nil {
+		onPanic = func(w http.ResponseWriter, r *http.Request, v interface{}) {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if v := recover(); v != nil {
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+					onPanic(w, r, v)
+				}
+			}()
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
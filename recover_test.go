@@ -0,0 +1,70 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var panickingApp = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	panic("broken")
+})
+
+func TestRecoverCallsOnPanic(t *testing.T) {
+	var recovered interface{}
+	onPanic := func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		recovered = v
+		w.WriteHeader(http.StatusTeapot)
+	}
+
+	chained := New(Recover(onPanic)).Then(panickingApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if recovered != "broken" {
+		t.Errorf("Recover does not pass the recovered value: %v", recovered)
+	}
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Recover does not let onPanic respond: %d", w.Code)
+	}
+}
+
+func TestRecoverRespondsInternalServerErrorByDefault(t *testing.T) {
+	chained := New(Recover(nil)).Then(panickingApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Recover should respond 500 by default, got %d", w.Code)
+	}
+}
+
+func TestRecoverDoesNotRecoverAbortHandler(t *testing.T) {
+	chained := New(Recover(nil)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Error("Recover should not recover from http.ErrAbortHandler")
+		}
+	}()
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chained.ServeHTTP(httptest.NewRecorder(), r)
+}
@@ -0,0 +1,77 @@
+package alice
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry maps names to middleware constructors,
+// so that chains can be assembled from configuration.
+//
+// The zero value is an empty registry ready to use.
+// A Registry is safe for concurrent use.
+//
+//	var reg alice.Registry
+//	reg.Register("logger", logger)
+//	reg.Register("auth", auth)
+//	chain, err := reg.Build("logger", "auth")
+//	// requests in chain go logger -> auth
+type Registry struct {
+	mu           sync.RWMutex
+	constructors map[string]Constructor
+}
+
+// Register makes a constructor available under the given name,
+// replacing any constructor previously registered under it.
+func (r *Registry) Register(name string, c Constructor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.constructors == nil {
+		r.constructors = make(map[string]Constructor)
+	}
+	r.constructors[name] = c
+}
+
+// Build creates a new chain
+// from the constructors registered under the given names, in order.
+// The constructors are wrapped with Named, using their registered names.
+// It returns an error if any of the names is not registered.
+func (r *Registry) Build(names ...string) (Chain, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	constructors := make([]Constructor, 0, len(names))
+	for _, name := range names {
+		c, ok := r.constructors[name]
+		if !ok {
+			return Chain{}, fmt.Errorf("alice: no constructor registered as %q", name)
+		}
+		constructors = append(constructors, Named(name, c))
+	}
+
+	return New(constructors...), nil
+}
+
+// NewOrdered creates a new chain
+// from the constructors of registry named in order, in that order,
+// wrapping them with Named as Registry.Build does.
+// It returns an error if a name of order is missing from registry,
+// or appears in order more than once.
+//
+//	chain, err := alice.NewOrdered(cfg.Middleware, map[string]alice.Constructor{
+//		"logger": logger,
+//		"auth":   auth,
+//	})
+func NewOrdered(order []string, registry map[string]Constructor) (Chain, error) {
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		if seen[name] {
+			return Chain{}, fmt.Errorf("alice: constructor %q ordered more than once", name)
+		}
+		seen[name] = true
+	}
+
+	reg := Registry{constructors: registry}
+	return reg.Build(order...)
+}
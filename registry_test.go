@@ -0,0 +1,74 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistryBuildsChainCorrectly(t *testing.T) {
+	var reg Registry
+	reg.Register("t1", tagMiddleware("t1\n"))
+	reg.Register("t2", tagMiddleware("t2\n"))
+	reg.Register("t3", tagMiddleware("t3\n"))
+
+	chain, err := reg.Build("t3", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.Then(testApp).ServeHTTP(w, r)
+
+	if w.Body.String() != "t3\nt1\napp\n" {
+		t.Error("Registry does not build the chain correctly")
+	}
+	if chain.String() != "Chain[2]{t3, t1}" {
+		t.Errorf("Registry does not name constructors correctly: %s", chain)
+	}
+}
+
+func TestRegistryRejectsUnknownName(t *testing.T) {
+	var reg Registry
+	reg.Register("t1", tagMiddleware("t1\n"))
+
+	if _, err := reg.Build("t1", "t2"); err == nil {
+		t.Error("Build should return an error for an unknown name")
+	}
+}
+
+func TestNewOrderedBuildsChainCorrectly(t *testing.T) {
+	registry := map[string]Constructor{
+		"t1": tagMiddleware("t1\n"),
+		"t2": tagMiddleware("t2\n"),
+		"t3": tagMiddleware("t3\n"),
+	}
+
+	chain, err := NewOrdered([]string{"t3", "t1"}, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	chain.Then(testApp).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Body.String() != "t3\nt1\napp\n" || chain.String() != "Chain[2]{t3, t1}" {
+		t.Errorf("NewOrdered does not build the chain correctly: %s", chain)
+	}
+}
+
+func TestNewOrderedRejectsBadOrder(t *testing.T) {
+	registry := map[string]Constructor{"t1": tagMiddleware("t1\n")}
+
+	if _, err := NewOrdered([]string{"t1", "t2"}, registry); err == nil || err.Error() != `alice: no constructor registered as "t2"` {
+		t.Errorf("NewOrdered returned %v for a missing name", err)
+	}
+	if _, err := NewOrdered([]string{"t1", "t1"}, registry); err == nil || err.Error() != `alice: constructor "t1" ordered more than once` {
+		t.Errorf("NewOrdered returned %v for a duplicate name", err)
+	}
+}
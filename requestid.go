@@ -0,0 +1,55 @@
+package alice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDKey is the context key under which
+// the middleware returned by RequestID stores the request ID.
+type RequestIDKey struct{}
+
+// RequestID returns a constructor for middleware
+// giving each request an ID, taken from the header of the request
+// or generated randomly if the request has none,
+// storing it in the request context under RequestIDKey{}
+// and setting it on the same header of the response.
+// An empty header means X-Request-ID.
+//
+//	alice.New(alice.RequestID("")).Then(h)
+func RequestID(header string) Constructor {
+	if header == "" {
+		header = "X-Request-ID"
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			if id == "" {
+				id = newRequestID()
+			}
+
+			w.Header().Set(header, id)
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), RequestIDKey{}, id)))
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID stored in ctx
+// by the middleware returned by RequestID,
+// or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey{}).(string)
+	return id
+}
+
+// newRequestID returns a random 128-bit ID, hex encoded.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("alice: cannot generate request ID: " + err.Error())
+	}
+	return hex.EncodeToString(b[:])
+}
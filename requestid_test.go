@@ -0,0 +1,44 @@
+package alice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDKeepsIncomingID(t *testing.T) {
+	var id string
+	chained := New(RequestID("X-Trace")).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = RequestIDFromContext(r.Context())
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Trace", "abc")
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, r)
+
+	if id != "abc" || w.Header().Get("X-Trace") != "abc" {
+		t.Errorf("RequestID did not keep the incoming ID: %q, %q", id, w.Header().Get("X-Trace"))
+	}
+}
+
+func TestRequestIDGeneratesID(t *testing.T) {
+	var id string
+	chained := New(RequestID("")).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = RequestIDFromContext(r.Context())
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if len(id) != 32 || w.Header().Get("X-Request-ID") != id {
+		t.Errorf("RequestID did not generate an ID: %q, %q", id, w.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestRequestIDFromContextWithoutID(t *testing.T) {
+	if id := RequestIDFromContext(context.Background()); id != "" {
+		t.Errorf("RequestIDFromContext returned %q", id)
+	}
+}
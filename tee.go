@@ -0,0 +1,55 @@
+package alice
+
+import (
+	"io"
+	"net/http"
+)
+
+// TeeResponse returns a constructor for middleware
+// copying the body of every response to out
+// as it is written to the client.
+// Errors writing to out are ignored:
+// they do not affect the response to the client,
+// and the rest of the response is not copied.
+//
+// out is shared by all requests,
+// so it must be safe for concurrent use if requests are.
+//
+//	var buf bytes.Buffer
+//	alice.New(alice.TeeResponse(&buf)).Then(h)
+func TeeResponse(out io.Writer) Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(&teeWriter{ResponseWriter: w, out: out}, r)
+		})
+	}
+}
+
+// teeWriter copies the body written through it to out.
+type teeWriter struct {
+	http.ResponseWriter
+	out    io.Writer
+	failed bool
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if n > 0 && !w.failed {
+		if _, teeErr := w.out.Write(b[:n]); teeErr != nil {
+			w.failed = true
+		}
+	}
+	return n, err
+}
+
+// Flush flushes the wrapped writer if it is an http.Flusher.
+func (w *teeWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *teeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
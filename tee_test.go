@@ -0,0 +1,48 @@
+package alice
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTeeResponseCopiesBody(t *testing.T) {
+	var buf bytes.Buffer
+	chained := New(tagMiddleware("t1\n"), TeeResponse(&buf)).Then(testApp)
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Body.String() != "t1\napp\n" || buf.String() != "app\n" {
+		t.Errorf("TeeResponse copied %q of %q", buf.String(), w.Body.String())
+	}
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("broken")
+}
+
+func TestTeeResponseIgnoresErrors(t *testing.T) {
+	out := &failingWriter{}
+	chained := New(TeeResponse(out)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello "))
+		w.Write([]byte("world"))
+	})
+
+	w := httptest.NewRecorder()
+	chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Body.String() != "hello world" {
+		t.Errorf("TeeResponse broke the response: %q", w.Body.String())
+	}
+	if out.writes != 1 {
+		t.Errorf("TeeResponse kept writing after an error: %d writes", out.writes)
+	}
+}
@@ -0,0 +1,32 @@
+package alice
+
+import (
+	"net/http"
+	"time"
+)
+
+// Timed returns a constructor that behaves like c,
+// but reports to sink how long each request spent in the middleware.
+//
+// The reported duration runs from the moment the request enters
+// the middleware to the moment the middleware returns,
+// so it includes the time spent in the handlers after it.
+// Comparing the durations of consecutive stages
+// tells how much time each of them adds.
+//
+//	alice.New(alice.Timed("auth", auth, func(name string, d time.Duration) {
+//		log.Printf("%s took %s", name, d)
+//	})).Then(h)
+func Timed(name string, c Constructor, sink func(name string, d time.Duration)) Constructor {
+	return func(h http.Handler) http.Handler {
+		wrapped := c(h)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			defer func() {
+				sink(name, time.Since(start))
+			}()
+
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimedReportsDurationsCorrectly(t *testing.T) {
+	var names []string
+	var durations []time.Duration
+	sink := func(name string, d time.Duration) {
+		names = append(names, name)
+		durations = append(durations, d)
+	}
+
+	slowApp := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("app\n"))
+	})
+
+	chained := New(
+		Timed("t1", tagMiddleware("t1\n"), sink),
+		Timed("t2", tagMiddleware("t2\n"), sink),
+	).Then(slowApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Body.String() != "t1\nt2\napp\n" {
+		t.Error("Timed does not behave like the timed constructor")
+	}
+	if len(names) != 2 || names[0] != "t2" || names[1] != "t1" {
+		t.Fatalf("Timed does not report names correctly: %v", names)
+	}
+	for i, d := range durations {
+		if d < 10*time.Millisecond {
+			t.Errorf("Timed should include downstream time for %s, got %s", names[i], d)
+		}
+	}
+	if durations[1] < durations[0] {
+		t.Error("Timed reports an outer stage as faster than an inner one")
+	}
+}
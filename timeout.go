@@ -0,0 +1,98 @@
+package alice
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Timeout returns a constructor for middleware
+// limiting the time the next handler has to serve each request,
+// as http.TimeoutHandler does.
+// Requests taking longer get a 503 Service Unavailable response
+// with http.TimeoutHandler's default body.
+//
+// Timeout panics if d is not positive.
+//
+//	alice.New(alice.Timeout(time.Second)).Then(h)
+func Timeout(d time.Duration) Constructor {
+	return TimeoutWithMessage(d, "")
+}
+
+// TimeoutWithMessage works like Timeout,
+// but responds to requests taking longer with msg as body.
+// An empty msg means http.TimeoutHandler's default body.
+//
+//	alice.New(alice.TimeoutWithMessage(time.Second, "timed out")).Then(h)
+func TimeoutWithMessage(d time.Duration, msg string) Constructor {
+	if d <= 0 {
+		panic("alice: non-positive duration for Timeout")
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.TimeoutHandler(h, d, msg)
+	}
+}
+
+// TimeoutWithCallback works like Timeout,
+// but also calls onTimeout with each request cut off
+// for taking longer than d, once the 503 response has been written,
+// to report slow handlers.
+//
+// onTimeout is called from the goroutine serving the request,
+// while the next handler may still be running in its own:
+// it must not use the request body.
+//
+//	alice.New(alice.TimeoutWithCallback(time.Second, func(r *http.Request) {
+//		log.Printf("timed out serving %s", r.URL)
+//	})).Then(h)
+func TimeoutWithCallback(d time.Duration, onTimeout func(*http.Request)) Constructor {
+	if d <= 0 {
+		panic("alice: non-positive duration for TimeoutWithCallback")
+	}
+
+	return func(h http.Handler) http.Handler {
+		// http.TimeoutHandler copies the headers of the next handler
+		// only when it finished in time:
+		// a response without the marker header is a timeout.
+		th := http.TimeoutHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer w.Header().Set(servedHeader, "1")
+			h.ServeHTTP(w, r)
+		}), d, "")
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// http.TimeoutHandler also responds 503 when the request is canceled,
+			// for instance by the client going away:
+			// only a deadline exceeded on this context is a timeout.
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{ResponseWriter: w}
+			th.ServeHTTP(tw, r.WithContext(ctx))
+
+			if tw.timedOut && ctx.Err() == context.DeadlineExceeded {
+				onTimeout(r)
+			}
+		})
+	}
+}
+
+// servedHeader marks the responses of handlers
+// that finished before TimeoutWithCallback cut them off.
+const servedHeader = "X-Alice-Served"
+
+// timeoutWriter records whether http.TimeoutHandler
+// wrote a timeout response through it,
+// removing the marker header of other responses.
+type timeoutWriter struct {
+	http.ResponseWriter
+	timedOut bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.Header().Get(servedHeader) == "" {
+		w.timedOut = true
+	}
+	w.Header().Del(servedHeader)
+	w.ResponseWriter.WriteHeader(code)
+}
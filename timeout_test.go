@@ -0,0 +1,126 @@
+package alice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutCutsSlowHandlers(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slowApp := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	chained := New(TimeoutWithMessage(10*time.Millisecond, "timed out")).Then(slowApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "timed out" {
+		t.Errorf("Timeout does not cut slow handlers: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeoutPassesFastHandlers(t *testing.T) {
+	chained := New(Timeout(time.Second)).Then(testApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chained.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK || w.Body.String() != "app\n" {
+		t.Errorf("Timeout does not pass fast handlers: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeoutPanicsOnNonPositiveDuration(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Timeout should panic on a non-positive duration")
+		}
+	}()
+
+	Timeout(0)
+}
+
+func TestTimeoutWithCallbackReportsSlowHandlers(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slowApp := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	var timedOut *http.Request
+	chained := New(TimeoutWithCallback(10*time.Millisecond, func(r *http.Request) {
+		timedOut = r
+	})).Then(slowApp)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	chained.ServeHTTP(w, r)
+
+	if w.Code != http.StatusServiceUnavailable || timedOut != r {
+		t.Errorf("TimeoutWithCallback does not report slow handlers: %d %v", w.Code, timedOut)
+	}
+}
+
+func TestTimeoutWithCallbackIgnoresFastHandlers(t *testing.T) {
+	unavailableApp := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	for _, h := range []http.Handler{testApp, unavailableApp} {
+		called := false
+		chained := New(TimeoutWithCallback(time.Second, func(r *http.Request) {
+			called = true
+		})).Then(h)
+
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if called {
+			t.Error("TimeoutWithCallback reports fast handlers")
+		}
+		if w.Header().Get(servedHeader) != "" {
+			t.Error("TimeoutWithCallback leaks its marker header")
+		}
+	}
+}
+
+func TestTimeoutWithCallbackIgnoresCanceledRequests(t *testing.T) {
+	slowApp := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	called := false
+	chained := New(TimeoutWithCallback(time.Second, func(r *http.Request) {
+		called = true
+	})).Then(slowApp)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	chained.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+	if called {
+		t.Error("TimeoutWithCallback reports canceled requests as timed out")
+	}
+}
@@ -0,0 +1,68 @@
+package alice
+
+import "net/http"
+
+// A constructor for a piece of client middleware,
+// wrapping an http.RoundTripper.
+type TransportConstructor func(http.RoundTripper) http.RoundTripper
+
+// TransportChain acts as a list of http.RoundTripper constructors,
+// the client-side counterpart of Chain.
+// TransportChain is effectively immutable:
+// once created, it will always hold
+// the same set of constructors in the same order.
+type TransportChain struct {
+	constructors []TransportConstructor
+}
+
+// NewTransport creates a new transport chain,
+// memorizing the given list of client middleware constructors.
+// Constructors are only called upon a call to Then().
+func NewTransport(constructors ...TransportConstructor) TransportChain {
+	return TransportChain{append(([]TransportConstructor)(nil), constructors...)}
+}
+
+// Then chains the client middleware and returns the final http.RoundTripper.
+//
+//	NewTransport(m1, m2, m3).Then(rt)
+//
+// is equivalent to:
+//
+//	m1(m2(m3(rt)))
+//
+// Outgoing requests will be passed to m1, then m2, then m3
+// and finally, the given round tripper;
+// responses flow back the other way.
+//
+// Then() treats nil as http.DefaultTransport.
+func (c TransportChain) Then(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	for i := range c.constructors {
+		rt = c.constructors[len(c.constructors)-1-i](rt)
+	}
+
+	return rt
+}
+
+// Append extends a transport chain, adding the specified constructors
+// as the last ones in the request flow.
+//
+// Append returns a new transport chain, leaving the original one untouched.
+func (c TransportChain) Append(constructors ...TransportConstructor) TransportChain {
+	newCons := make([]TransportConstructor, 0, len(c.constructors)+len(constructors))
+	newCons = append(newCons, c.constructors...)
+	newCons = append(newCons, constructors...)
+
+	return TransportChain{newCons}
+}
+
+// Extend extends a transport chain by adding the specified transport chain
+// as the last one in the request flow.
+//
+// Extend returns a new transport chain, leaving the original one untouched.
+func (c TransportChain) Extend(chain TransportChain) TransportChain {
+	return c.Append(chain.constructors...)
+}
@@ -0,0 +1,99 @@
+package alice
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// A constructor for client middleware
+// that logs its own tag before and after the round trip.
+func tagTransport(tag string, log *[]string) TransportConstructor {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			*log = append(*log, tag+" request")
+			resp, err := rt.RoundTrip(r)
+			*log = append(*log, tag+" response")
+			return resp, err
+		})
+	}
+}
+
+func fakeTransport(log *[]string) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		*log = append(*log, "base")
+		return &http.Response{StatusCode: http.StatusOK, Request: r}, nil
+	})
+}
+
+func TestTransportThenOrdersRoundTrippersCorrectly(t *testing.T) {
+	var log []string
+	rt := NewTransport(tagTransport("t1", &log), tagTransport("t2", &log)).Then(fakeTransport(&log))
+
+	r, err := http.NewRequest("GET", "http://example.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := rt.RoundTrip(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Error("Then does not return the base round tripper response")
+	}
+
+	expected := "t1 request, t2 request, base, t2 response, t1 response"
+	if strings.Join(log, ", ") != expected {
+		t.Errorf("Then does not order round trippers correctly: %v", log)
+	}
+}
+
+func TestTransportThenTreatsNilAsDefaultTransport(t *testing.T) {
+	if NewTransport().Then(nil) != http.DefaultTransport {
+		t.Error("Then does not treat nil as DefaultTransport")
+	}
+}
+
+func TestTransportAppendAndExtendAddRoundTrippersCorrectly(t *testing.T) {
+	var log []string
+	chain := NewTransport(tagTransport("t1", &log))
+	newChain := chain.Append(tagTransport("t2", &log)).Extend(NewTransport(tagTransport("t3", &log)))
+
+	if len(chain.constructors) != 1 {
+		t.Error("chain should have 1 constructor")
+	}
+	if len(newChain.constructors) != 3 {
+		t.Error("newChain should have 3 constructors")
+	}
+
+	r, err := http.NewRequest("GET", "http://example.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newChain.Then(fakeTransport(&log)).RoundTrip(r); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "t1 request, t2 request, t3 request, base, t3 response, t2 response, t1 response"
+	if strings.Join(log, ", ") != expected {
+		t.Errorf("Append and Extend do not add round trippers correctly: %v", log)
+	}
+}
+
+func TestTransportAppendRespectsImmutability(t *testing.T) {
+	var log []string
+	chain := NewTransport(tagTransport("", &log))
+	newChain := chain.Append(tagTransport("", &log))
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("Append does not respect immutability")
+	}
+}
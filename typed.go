@@ -0,0 +1,86 @@
+package alice
+
+import "net/http"
+
+// HandlerOf is a handler receiving,
+// along with the request, a value of type T
+// shared by the middleware of a ChainOf.
+type HandlerOf[T any] func(http.ResponseWriter, *http.Request, T)
+
+// Handler returns an http.Handler serving requests with h,
+// passing it the value returned by value for each request.
+//
+//	type state struct{ user string }
+//	h := chain.Then(app).Handler(func(*http.Request) *state {
+//		return &state{}
+//	})
+func (h HandlerOf[T]) Handler(value func(*http.Request) T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h(w, r, value(r))
+	})
+}
+
+// A constructor for a piece of middleware of a ChainOf.
+type ConstructorOf[T any] func(HandlerOf[T]) HandlerOf[T]
+
+// ChainOf is the counterpart of Chain for handlers sharing a value of type T,
+// which lets middleware pass state to each other with static typing
+// instead of going through the request context.
+// ChainOf is effectively immutable:
+// once created, it will always hold
+// the same set of constructors in the same order.
+type ChainOf[T any] struct {
+	constructors []ConstructorOf[T]
+}
+
+// NewOf creates a new typed chain,
+// memorizing the given list of middleware constructors.
+// Constructors are only called upon a call to Then().
+func NewOf[T any](constructors ...ConstructorOf[T]) ChainOf[T] {
+	return ChainOf[T]{append(([]ConstructorOf[T])(nil), constructors...)}
+}
+
+// Then chains the middleware and returns the final HandlerOf,
+// in the same order as Chain.Then:
+//
+//	NewOf(m1, m2, m3).Then(h)
+//
+// is equivalent to:
+//
+//	m1(m2(m3(h)))
+//
+// Then() treats nil as a handler serving requests
+// with http.DefaultServeMux, ignoring the shared value.
+func (c ChainOf[T]) Then(h HandlerOf[T]) HandlerOf[T] {
+	if h == nil {
+		h = func(w http.ResponseWriter, r *http.Request, _ T) {
+			http.DefaultServeMux.ServeHTTP(w, r)
+		}
+	}
+
+	for i := range c.constructors {
+		h = c.constructors[len(c.constructors)-1-i](h)
+	}
+
+	return h
+}
+
+// Append extends a typed chain, adding the specified constructors
+// as the last ones in the request flow.
+//
+// Append returns a new typed chain, leaving the original one untouched.
+func (c ChainOf[T]) Append(constructors ...ConstructorOf[T]) ChainOf[T] {
+	newCons := make([]ConstructorOf[T], 0, len(c.constructors)+len(constructors))
+	newCons = append(newCons, c.constructors...)
+	newCons = append(newCons, constructors...)
+
+	return ChainOf[T]{newCons}
+}
+
+// Extend extends a typed chain by adding the specified typed chain
+// as the last one in the request flow.
+//
+// Extend returns a new typed chain, leaving the original one untouched.
+func (c ChainOf[T]) Extend(chain ChainOf[T]) ChainOf[T] {
+	return c.Append(chain.constructors...)
+}
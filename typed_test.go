@@ -0,0 +1,89 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type typedState struct {
+	tags []string
+}
+
+// A typed constructor for middleware
+// that records its own tag into the shared state.
+func typedTagMiddleware(tag string) ConstructorOf[*typedState] {
+	return func(h HandlerOf[*typedState]) HandlerOf[*typedState] {
+		return func(w http.ResponseWriter, r *http.Request, s *typedState) {
+			s.tags = append(s.tags, tag)
+			h(w, r, s)
+		}
+	}
+}
+
+func typedApp(w http.ResponseWriter, r *http.Request, s *typedState) {
+	for _, tag := range s.tags {
+		w.Write([]byte(tag))
+	}
+	w.Write([]byte("app\n"))
+}
+
+func newTypedState(*http.Request) *typedState {
+	return &typedState{}
+}
+
+func TestChainOfPassesValueCorrectly(t *testing.T) {
+	chain := NewOf(typedTagMiddleware("t1\n"))
+	newChain := chain.Append(typedTagMiddleware("t2\n")).Extend(NewOf(typedTagMiddleware("t3\n")))
+
+	if len(chain.constructors) != 1 {
+		t.Error("chain should have 1 constructor")
+	}
+	if len(newChain.constructors) != 3 {
+		t.Error("newChain should have 3 constructors")
+	}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newChain.Then(typedApp).Handler(newTypedState).ServeHTTP(w, r)
+
+		if w.Body.String() != "t1\nt2\nt3\napp\n" {
+			t.Errorf("ChainOf does not pass the value correctly: %q", w.Body.String())
+		}
+	}
+}
+
+func TestChainOfAppendRespectsImmutability(t *testing.T) {
+	chain := NewOf(typedTagMiddleware(""))
+	newChain := chain.Append(typedTagMiddleware(""))
+
+	if &chain.constructors[0] == &newChain.constructors[0] {
+		t.Error("Append does not respect immutability")
+	}
+}
+
+func TestChainOfThenTreatsNilAsDefaultServeMux(t *testing.T) {
+	mux := http.DefaultServeMux
+	defer func() {
+		http.DefaultServeMux = mux
+	}()
+	http.DefaultServeMux = http.NewServeMux()
+	http.DefaultServeMux.Handle("/", testApp)
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	NewOf(typedTagMiddleware("t1\n")).Then(nil).Handler(newTypedState).ServeHTTP(w, r)
+
+	if w.Body.String() != "app\n" {
+		t.Error("Then does not treat nil as DefaultServeMux")
+	}
+}